@@ -16,11 +16,14 @@ func (c *Client) Get(query *CtxQuery, output any) error {
 	if err != nil {
 		return err
 	}
+	c.tableName(parsed.TableName)
 	switch out, err := c.SDK().GetItem(query.Context(), parsed); {
 	case err != nil:
 		return err
 	case len(out.Item) == 0:
 		return ErrNotFoundItem
+	case query.registry != nil:
+		return query.registry.unmarshal(out.Item, output)
 	default:
 		return UnmarshalMapByAny(out.Item, output)
 	}
@@ -35,14 +38,21 @@ func (c *Client) Query(query *CtxQuery, output any) (int32, PaginationKey, error
 	if err != nil {
 		return 0, nil, err
 	}
+	c.tableName(parsed.TableName)
 	out, err := c.SDK().Query(query.Context(), parsed)
 	if err != nil {
 		return 0, nil, err
 	}
-	if query.selectAttr != types.SelectCount && out.Count > 0 {
-		err = UnmarshalListOfMapsByAny(out.Items, output)
+	if query.selectAttr == types.SelectCount || out.Count == 0 {
+		return out.Count, out.LastEvaluatedKey, nil
 	}
-	return out.Count, out.LastEvaluatedKey, err
+	items := out.Items
+	if query.hydrating() {
+		if items, err = c.hydrate(query, *parsed.TableName, items, output); err != nil {
+			return 0, nil, err
+		}
+	}
+	return int32(len(items)), out.LastEvaluatedKey, query.unmarshalList(items, output)
 }
 
 func (c *Client) Scan(query *CtxQuery, output any) (int32, PaginationKey, error) {
@@ -54,12 +64,13 @@ func (c *Client) Scan(query *CtxQuery, output any) (int32, PaginationKey, error)
 	if err != nil {
 		return 0, nil, err
 	}
+	c.tableName(parsed.TableName)
 	out, err := c.SDK().Scan(query.Context(), parsed)
 	if err != nil {
 		return 0, nil, err
 	}
 	if query.selectAttr != types.SelectCount && out.Count > 0 {
-		err = UnmarshalListOfMapsByAny(out.Items, output)
+		err = query.unmarshalList(out.Items, output)
 	}
 	return out.Count, out.LastEvaluatedKey, err
 }
@@ -76,6 +87,7 @@ func (c *Client) Put(query *CtxQuery, outputOps ...any) error {
 	if err != nil {
 		return err
 	}
+	c.tableName(parsed.TableName)
 	switch out, err := c.SDK().PutItem(query.Context(), parsed); {
 	case err != nil || len(outputOps) == 0:
 		return err
@@ -95,6 +107,7 @@ func (c *Client) Update(query *CtxQuery, outputOps ...any) error {
 	if err != nil {
 		return err
 	}
+	c.tableName(parsed.TableName)
 	switch out, err := c.SDK().UpdateItem(query.Context(), parsed); {
 	case err != nil || len(outputOps) == 0:
 		return err
@@ -117,6 +130,7 @@ func (c *Client) Delete(query *CtxQuery, outputOps ...any) error {
 	if err != nil {
 		return err
 	}
+	c.tableName(parsed.TableName)
 	switch out, err := c.SDK().DeleteItem(query.Context(), parsed); {
 	case err != nil || len(outputOps) == 0:
 		return err
@@ -144,10 +158,13 @@ func (c *Client) BatchWriteWithCallBack(query *CtxQuery, callback BatchWriteCall
 	if err != nil {
 		return err
 	}
+	var logical map[string]string
+	parsed.RequestItems, logical = tableNames(c, parsed.RequestItems)
 	out, err := c.SDK().BatchWriteItem(query.Context(), parsed)
 	if err != nil {
 		return err
 	}
+	out.UnprocessedItems = logicalTables(out.UnprocessedItems, logical)
 	return callback(out)
 }
 
@@ -165,10 +182,14 @@ func (c *Client) BatchGetWithCallBack(query *CtxQuery, callback BatchGetCallback
 	if err != nil {
 		return err
 	}
+	var logical map[string]string
+	parsed.RequestItems, logical = tableNames(c, parsed.RequestItems)
 	out, err := c.SDK().BatchGetItem(query.Context(), parsed)
 	if err != nil {
 		return err
 	}
+	out.Responses = logicalTables(out.Responses, logical)
+	out.UnprocessedKeys = logicalTables(out.UnprocessedKeys, logical)
 	return callback(out)
 }
 
@@ -177,6 +198,7 @@ func (c *Client) TransactionWrite(query *CtxQuery) error {
 	if err != nil {
 		return err
 	}
+	parsed.TransactItems = c.transactWriteTableNames(parsed.TransactItems)
 	_, err = c.SDK().TransactWriteItems(query.Context(), parsed)
 	return err
 }
@@ -198,6 +220,7 @@ func (c *Client) TransactionGetWithCallBack(query *CtxQuery, callback Transactio
 	if err != nil {
 		return err
 	}
+	parsed.TransactItems = c.transactGetTableNames(parsed.TransactItems)
 	out, err := c.SDK().TransactGetItems(query.Context(), parsed)
 	if err != nil {
 		return err
@@ -5,10 +5,11 @@ import (
 	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
 func (c *cruder) Exist(ctx context.Context, keyedItem KeyedItem, skipSK bool, consistent ...bool) (bool, error) {
-	err := Prepare(keyedItem, skipSK)
+	err := preMarshalWith(keyedItem, c.cli().Conventions(), skipSK)
 	if err != nil {
 		return false, err
 	}
@@ -26,15 +27,16 @@ func (c *cruder) Exist(ctx context.Context, keyedItem KeyedItem, skipSK bool, co
 		ExprQuery(expr).
 		SetLimit(1).
 		Count()
-	if len(consistent) > 0 {
-		query.SetConsistentRead(consistent[0])
-	}
+	query.SetConsistentRead(c.cli().consistentRead(consistent))
 	cnt, _, err := c.cli().Query(query, nil)
 	return cnt == 1, err
 }
 
 func (c *cruder) Create(ctx context.Context, keyedItem KeyedItem, strictPk bool) error {
-	item, err := MarshalMap(keyedItem)
+	if err := c.cli().fillDefaults(ctx, keyedItem); err != nil {
+		return err
+	}
+	item, err := marshalMap(keyedItem, c.cli().Conventions())
 	if err != nil {
 		return err
 	}
@@ -44,27 +46,37 @@ func (c *cruder) Create(ctx context.Context, keyedItem KeyedItem, strictPk bool)
 		cond := fmt.Sprintf("attribute_not_exists(%s)", keyedItem.PKField())
 		query.SetCondExpr(&cond)
 	}
-	return c.cli().Put(query)
+	if !c.bus.hasSubscriber(keyedItem) {
+		return c.cli().Put(query)
+	}
+
+	var old attrMap
+	if err = c.cli().Put(query.SetReturnValues(types.ReturnValueAllOld), &old); err != nil {
+		return err
+	}
+	key, err := c.cli().MarshalMapOnlyKey(keyedItem, true)
+	if err != nil {
+		return err
+	}
+	return c.bus.publishWrite(ctx, OpCreate, keyedItem, key, old)
 }
 
 func (c *cruder) Read(ctx context.Context, keyedItem KeyedItem, consistent ...bool) error {
-	key, err := MarshalMapOnlyKey(keyedItem)
+	key, err := c.cli().MarshalMapOnlyKey(keyedItem)
 	if err != nil {
 		return err
 	}
-	query := NewCtxQuery(ctx).SetTable(keyedItem.Table()).SetKey(key)
-	if len(consistent) > 0 {
-		query.SetConsistentRead(consistent[0])
-	}
+	query := NewCtxQuery(ctx).SetTable(keyedItem.Table()).SetKey(key).
+		SetConsistentRead(c.cli().consistentRead(consistent))
 	return c.cli().Get(query, keyedItem)
 }
 
 func (c *cruder) Update(ctx context.Context, keyedItem KeyedItem, strictPk bool) error {
-	key, err := MarshalMapOnlyKey(keyedItem)
+	key, err := c.cli().MarshalMapOnlyKey(keyedItem)
 	if err != nil {
 		return err
 	}
-	SetUpdatedAt(keyedItem)
+	callMethod(keyedItem, (*Model)(nil), "SetUpdatedAtBy", c.cli().now(ctx))
 
 	var cond []expression.ConditionBuilder
 	if strictPk {
@@ -75,25 +87,33 @@ func (c *cruder) Update(ctx context.Context, keyedItem KeyedItem, strictPk bool)
 		return err
 	}
 	query := NewCtxQuery(ctx).SetTable(keyedItem.Table()).SetKey(key).ExprUpdate(expr)
-	return c.cli().Update(query)
+	return c.updateAndPublish(query, OpUpdate, keyedItem, key)
 }
 
 func (c *cruder) Delete(ctx context.Context, keyedItem KeyedItem) error {
-	key, err := MarshalMapOnlyKey(keyedItem)
+	key, err := c.cli().MarshalMapOnlyKey(keyedItem)
 	if err != nil {
 		return err
 	}
 	query := NewCtxQuery(ctx).SimpleDelete(keyedItem.Table(), key)
-	return c.cli().Delete(query)
+	if !c.bus.hasSubscriber(keyedItem) {
+		return c.cli().Delete(query)
+	}
+
+	var old attrMap
+	if err = c.cli().Delete(query.SetReturnValues(types.ReturnValueAllOld), &old); err != nil {
+		return err
+	}
+	return c.bus.publishWrite(ctx, OpDelete, keyedItem, key, old)
 }
 
 func (c *cruder) DeleteSoft(ctx context.Context, keyedItem KeyedItem) error {
 	if !HasModel(keyedItem) {
 		return ErrUnembedModel
 	}
-	deletedAt := TimeNow().Int64()
+	deletedAt := c.cli().now(ctx).Int64()
 
-	key, err := MarshalMapOnlyKey(keyedItem)
+	key, err := c.cli().MarshalMapOnlyKey(keyedItem)
 	if err != nil {
 		return err
 	}
@@ -106,5 +126,46 @@ func (c *cruder) DeleteSoft(ctx context.Context, keyedItem KeyedItem) error {
 		return err
 	}
 	query := NewCtxQuery(ctx).SetTable(keyedItem.Table()).SetKey(key).ExprUpdate(expr)
-	return c.cli().Update(query)
+	return c.updateAndPublish(query, OpDeleteSoft, keyedItem, key)
+}
+
+func (c *cruder) updateAndPublish(query *CtxQuery, op writeOp, keyedItem KeyedItem, key map[string]types.AttributeValue) error {
+	if !c.bus.hasSubscriber(keyedItem) {
+		return c.cli().Update(query)
+	}
+
+	// keyedItem may be partial, the event carries the item as stored
+	var stored attrMap
+	if err := c.cli().Update(query.SetReturnValues(types.ReturnValueAllNew), &stored); err != nil {
+		return err
+	}
+	return c.bus.publishUpdate(query.Context(), op, keyedItem, key, stored)
+}
+
+// ReadPartition reads every item sharing keyBase's partition key into a Bundle,
+// decoded by Options.EntityRegistry
+func (c *cruder) ReadPartition(ctx context.Context, keyBase KeyBase, consistent ...bool) (*Bundle, error) {
+	registry := c.opts.EntityRegistry
+	if registry == nil {
+		return nil, ErrRequiredEntityRegistry
+	}
+	if err := preMarshal(keyBase); err != nil {
+		return nil, err
+	}
+
+	bundle := NewBundle(registry, keyBase)
+	kcb := NewKeyCondBuilder().WithPK(keyBase.PKField(), keyBase.PK())
+	var startKey PaginationKey
+	for {
+		query := NewCtxQuery(ctx).SetTable(keyBase.Table()).SetKeyCondBuilder(kcb).SetOrderByAsc(true).SetStartKey(startKey).
+			SetConsistentRead(c.cli().consistentRead(consistent))
+		_, lastEvaluatedKey, err := c.cli().Query(query, bundle)
+		if err != nil {
+			return nil, err
+		}
+		if len(lastEvaluatedKey) == 0 {
+			return bundle, nil
+		}
+		startKey = lastEvaluatedKey
+	}
 }
@@ -0,0 +1,157 @@
+package dynamox
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// hydrateAttempts bounds the BatchGet retries of UnprocessedKeys per chunk
+const hydrateAttempts = 5
+
+// hydrating reports whether the query reads a partial projection (KEYS_ONLY, INCLUDE) to be hydrated
+func (cq CtxQuery) hydrating() bool {
+	idx, ok := cq.queryIndex()
+	return cq.hydrate && ok && idx.ProjectionType() != types.ProjectionTypeAll
+}
+
+func (cq CtxQuery) queryIndex() (Index, bool) {
+	if cq.keyCondBuilder != nil {
+		if idx, ok := cq.keyCondBuilder.Index(); ok {
+			return idx, true
+		}
+	}
+	if cq.indexOf != nil {
+		return *cq.indexOf, true
+	}
+	return Index{}, false
+}
+
+// tableKeyFields derives the primary key fields of the table, by the EntityRegistry or the output type
+func (cq CtxQuery) tableKeyFields(output any) (pkField, skField string, err error) {
+	registry := cq.registry
+	if b, ok := output.(*Bundle); ok && registry == nil {
+		registry = b.registry
+	}
+	if registry != nil {
+		if item, ok := registry.proto(); ok {
+			return item.PKField(), item.SKField(), nil
+		}
+	}
+	typ := reflect.TypeOf(output)
+	for typ != nil && (typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+		typ = typ.Elem()
+	}
+	if typ != nil && typ.Kind() == reflect.Struct {
+		if item := newEntityOf(typ); item != nil && item.PKField() != "" {
+			return item.PKField(), item.SKField(), nil
+		}
+	}
+	return "", "", fmt.Errorf("%w: %T", ErrUnderivableTableKey, output)
+}
+
+// hydrate fetches the full items of the index items l by chunked BatchGet, in the order of l;
+// items deleted since the index was read are left out
+func (c *Client) hydrate(query *CtxQuery, table string, l []map[string]types.AttributeValue, output any) ([]map[string]types.AttributeValue, error) {
+	pkField, skField, err := query.tableKeyFields(output)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]map[string]types.AttributeValue, 0, len(l))
+	ids := make([]string, 0, len(l))
+	seen := make(map[string]struct{}, len(l))
+	for _, m := range l {
+		key, id, err := tableKeyOf(m, pkField, skField)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		seen[id] = struct{}{}
+		keys, ids = append(keys, key), append(ids, id)
+	}
+
+	kaa := types.KeysAndAttributes{ConsistentRead: aws.Bool(query.consistentRead)}
+	if query.projection != nil {
+		kaa.ProjectionExpression, kaa.ExpressionAttributeNames = projectionExpr(query.projection, nil, pkField, skField)
+	}
+	found := make(map[string]map[string]types.AttributeValue, len(keys))
+	for chunk := range slices.Chunk(keys, BatchGetLimit) {
+		kaa.Keys = chunk
+		if err = c.batchGetAll(query.Context(), table, kaa, pkField, skField, found); err != nil {
+			return nil, err
+		}
+	}
+	hydrated := make([]map[string]types.AttributeValue, 0, len(ids))
+	for _, id := range ids {
+		if m, ok := found[id]; ok {
+			hydrated = append(hydrated, m)
+		}
+	}
+	return hydrated, nil
+}
+
+// batchGetAll gets the keys of kaa into found by key id, retrying UnprocessedKeys with backoff
+func (c *Client) batchGetAll(ctx context.Context, table string, kaa types.KeysAndAttributes, pkField, skField string, found map[string]map[string]types.AttributeValue) error {
+	request := map[string]types.KeysAndAttributes{table: kaa}
+	for attempt := range hydrateAttempts {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(1<<attempt) * 25 * time.Millisecond):
+			}
+		}
+		out, err := c.SDK().BatchGetItem(ctx, &dynamodb.BatchGetItemInput{RequestItems: request})
+		if err != nil {
+			return err
+		}
+		for _, m := range out.Responses[table] {
+			_, id, err := tableKeyOf(m, pkField, skField)
+			if err != nil {
+				return err
+			}
+			found[id] = m
+		}
+		if len(out.UnprocessedKeys[table].Keys) == 0 {
+			return nil
+		}
+		request = out.UnprocessedKeys
+	}
+	return fmt.Errorf("%w: %d keys of %s after %d attempts", ErrUnprocessedItems, len(request[table].Keys), table, hydrateAttempts)
+}
+
+// tableKeyOf picks the primary key out of m, with an id comparable across the index and the table
+func tableKeyOf(m map[string]types.AttributeValue, pkField, skField string) (map[string]types.AttributeValue, string, error) {
+	key := make(map[string]types.AttributeValue, 2)
+	var sb strings.Builder
+	for _, field := range []string{pkField, skField} {
+		if field == "" {
+			continue
+		}
+		v, ok := m[field]
+		if !ok {
+			return nil, "", fmt.Errorf("%w: %s not in the index item", ErrUnderivableTableKey, field)
+		}
+		key[field] = v
+		switch v := v.(type) {
+		case *types.AttributeValueMemberS:
+			fmt.Fprintf(&sb, "S%d:%s", len(v.Value), v.Value)
+		case *types.AttributeValueMemberN:
+			fmt.Fprintf(&sb, "N%d:%s", len(v.Value), v.Value)
+		case *types.AttributeValueMemberB:
+			fmt.Fprintf(&sb, "B%d:%s", len(v.Value), v.Value)
+		default:
+			return nil, "", fmt.Errorf("%w: %s is %T", ErrUnsupportedAttrValueTypeForKey, field, v)
+		}
+	}
+	return key, sb.String(), nil
+}
@@ -1,38 +1,87 @@
 package dynamox
 
 import (
+	"maps"
 	"reflect"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
+// conventionalKeyBase is a KeyBase composing its own keys, e.g. the pk of a Tagged item
+type conventionalKeyBase interface {
+	preMarshalWith(cv Conventions) error
+}
+
 func preMarshal(keybase KeyBase) error {
+	return preMarshalKey(keybase, GlobalConventions())
+}
+
+func preMarshalKey(keybase KeyBase, cv Conventions) error {
+	if ck, ok := keybase.(conventionalKeyBase); ok {
+		return ck.preMarshalWith(cv)
+	}
 	if hooker, ok := keybase.(KeyBaseHooker); ok {
 		return hooker.PreMarshal()
 	}
 	return nil
 }
 
+// saveSK prefers the ConventionalItem form so a Client's separator reaches the sort key
+func saveSK(item KeyedItem, cv Conventions) error {
+	if ci, ok := item.(ConventionalItem); ok {
+		return ci.SaveSKWith(cv)
+	}
+	return item.SaveSK()
+}
+
 func PreMarshal(item KeyedItem, skipSaveSK ...bool) error {
-	skip := len(skipSaveSK) > 0 && skipSaveSK[0]
+	return preMarshalWith(item, GlobalConventions(), len(skipSaveSK) > 0 && skipSaveSK[0])
+}
+
+func preMarshalWith(item KeyedItem, cv Conventions, skip bool) error {
 	if !skip {
-		if err := item.SaveSK(); err != nil {
+		if err := saveSK(item, cv); err != nil {
 			return err
 		}
 	}
-	return preMarshal(item.GetKeyBase())
+	return preMarshalKey(item.GetKeyBase(), cv)
 }
 
 func Prepare(item KeyedItem, skipSaveSK ...bool) error {
 	return PreMarshal(item, skipSaveSK...)
 }
 
+// MarshalMap generates empty ID / IDSeq fields into item before SaveSK, then marshals it
+// with the index key attributes of an IndexedItem
 func MarshalMap(item KeyedItem, skipSaveSK ...bool) (map[string]types.AttributeValue, error) {
-	if err := PreMarshal(item); err != nil {
+	if err := fillDefaults(item, nil, nil, nil); err != nil {
 		return nil, err
 	}
-	return attributevalue.MarshalMap(item)
+	return marshalMap(item, GlobalConventions())
+}
+
+func marshalMap(item KeyedItem, cv Conventions) (map[string]types.AttributeValue, error) {
+	if err := preMarshalWith(item, cv, false); err != nil {
+		return nil, err
+	}
+	values, removes, err := indexKeyValues(item, false)
+	if err != nil {
+		return nil, err
+	}
+	m, err := attributevalue.MarshalMap(item)
+	if err != nil || values == nil {
+		return m, err
+	}
+	indexAttrs, err := attributevalue.MarshalMap(values)
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(m, indexAttrs)
+	for _, field := range removes {
+		delete(m, field)
+	}
+	return m, nil
 }
 
 func MarshalMapByAny(item any, skipSaveSK ...bool) (map[string]types.AttributeValue, error) {
@@ -48,17 +97,14 @@ func MarshalMapByAny(item any, skipSaveSK ...bool) (map[string]types.AttributeVa
 }
 
 func MarshalMapOnlyKey(item KeyedItem, skipSaveSK ...bool) (map[string]types.AttributeValue, error) {
-	skip := len(skipSaveSK) > 0 && skipSaveSK[0]
-	if !skip {
-		if err := item.SaveSK(); err != nil {
-			return nil, err
-		}
-	}
-	keybase := item.GetKeyBase()
-	if err := preMarshal(keybase); err != nil {
+	return marshalMapOnlyKey(item, GlobalConventions(), len(skipSaveSK) > 0 && skipSaveSK[0])
+}
+
+func marshalMapOnlyKey(item KeyedItem, cv Conventions, skip bool) (map[string]types.AttributeValue, error) {
+	if err := preMarshalWith(item, cv, skip); err != nil {
 		return nil, err
 	}
-	return attributevalue.MarshalMap(keybase)
+	return attributevalue.MarshalMap(item.GetKeyBase())
 }
 
 func postUnmarshal(keybase KeyBase) error {
@@ -31,7 +31,9 @@ type (
 		SetKey(m map[string]types.AttributeValue) *CtxQuery
 		SetConsistentRead(cr bool) *CtxQuery
 		SetStartKey(m PaginationKey) *CtxQuery
+		SetStartToken(token string, sealer *TokenSealer) *CtxQuery
 		SetIndex(idx string) *CtxQuery
+		SetIndexOf(idx Index) *CtxQuery
 		SetLimit(l int32) *CtxQuery
 		SetOrderByAsc(asc bool) *CtxQuery
 		SetSelectAttr(s types.Select) *CtxQuery
@@ -50,6 +52,9 @@ type (
 		SetReturnValues(rv types.ReturnValue) *CtxQuery
 		SetReturnValuesOnConditionCheckFailure(rv types.ReturnValuesOnConditionCheckFailure) *CtxQuery
 		SetKeyCondBuilder(kcb *KeyCondBuilder) *CtxQuery
+		SetEntityRegistry(r *EntityRegistry) *CtxQuery
+		SetProjectionOf(v any) *CtxQuery
+		SetHydrate(h bool) *CtxQuery
 
 		AppendBatchWriteItems(table string, items []types.WriteRequest) *CtxQuery
 		AppendTransactionWriteItems(items []types.TransactWriteItem) *CtxQuery
@@ -81,6 +86,7 @@ type (
 		Update(ctx context.Context, keyedItem KeyedItem, strictPk bool) error
 		Delete(ctx context.Context, keyedItem KeyedItem) error
 		DeleteSoft(ctx context.Context, keyedItem KeyedItem) error
+		ReadPartition(ctx context.Context, keyBase KeyBase, consistent ...bool) (*Bundle, error)
 	}
 
 	controlPlane interface {
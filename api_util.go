@@ -1 +1,59 @@
 package dynamox
+
+import (
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// attrMap receives raw attributes through Client outputs without decoding
+type attrMap map[string]types.AttributeValue
+
+var _ attributevalue.Unmarshaler = (*attrMap)(nil)
+
+func (am *attrMap) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	m, ok := av.(*types.AttributeValueMemberM)
+	if !ok {
+		return ErrExpectedMapAttribute
+	}
+	*am = m.Value
+	return nil
+}
+
+// entityAdapter is a KeyedItem wrapping another struct, such as Tagged
+type entityAdapter interface {
+	entityType() reflect.Type
+	newEntity() KeyedItem
+}
+
+// entityTypeOf returns the struct type behind item, unwrapping adapters
+func entityTypeOf(item KeyedItem) reflect.Type {
+	if adapter, ok := item.(entityAdapter); ok {
+		return adapter.entityType()
+	}
+	return indirectType(reflect.TypeOf(item))
+}
+
+// newEntityOf returns a new zero item of struct type typ, adapting tagged structs
+func newEntityOf(typ reflect.Type) KeyedItem {
+	ptr := reflect.New(typ).Interface()
+	if item, ok := ptr.(KeyedItem); ok {
+		return item
+	}
+	item, _ := Tagged(ptr)
+	return item
+}
+
+// newKeyedItemOf returns a new zero value of the same concrete type as item
+func newKeyedItemOf(item KeyedItem) (KeyedItem, bool) {
+	if adapter, ok := item.(entityAdapter); ok {
+		return adapter.newEntity(), true
+	}
+	typ := indirectType(reflect.TypeOf(item))
+	if typ.Kind() != reflect.Struct {
+		return nil, false
+	}
+	newItem, ok := reflect.New(typ).Interface().(KeyedItem)
+	return newItem, ok
+}
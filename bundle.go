@@ -0,0 +1,89 @@
+package dynamox
+
+import (
+	"iter"
+	"reflect"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var (
+	_ KeyedItemBundle            = (*Bundle)(nil)
+	_ attributevalue.Unmarshaler = (*Bundle)(nil)
+)
+
+// Bundle is a generic KeyedItemBundle; items are decoded through the EntityRegistry
+// and kept per entity type, in query order. Tagged entities are looked up by their own struct type.
+//
+//	bundle := NewBundle(registry, keyBase)
+//	cli.Query(query, bundle)
+//	profile, _ := BundleFirst[*Profile](bundle)
+//	bookmarks := BundleItems[*Bookmark](bundle)
+type Bundle struct {
+	KeyBase  // partition, as given
+	registry *EntityRegistry
+	items    []KeyedItem
+	byType   map[reflect.Type][]KeyedItem
+}
+
+func NewBundle(registry *EntityRegistry, keyBase KeyBase) *Bundle {
+	return &Bundle{KeyBase: keyBase, registry: registry, byType: make(map[reflect.Type][]KeyedItem)}
+}
+
+func (b *Bundle) Count() int { return len(b.items) }
+
+func (b *Bundle) Iterator() iter.Seq[KeyedItem] { return slices.Values(b.items) }
+
+func (b *Bundle) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	if b.registry == nil {
+		return ErrRequiredEntityRegistry
+	}
+	if err := CheckListOfMaps(av); err != nil {
+		return err
+	}
+	l := av.(*types.AttributeValueMemberL).Value
+	list := make([]map[string]types.AttributeValue, 0, len(l))
+	for _, v := range l {
+		list = append(list, v.(*types.AttributeValueMemberM).Value)
+	}
+	return b.append(list)
+}
+
+func (b *Bundle) append(l []map[string]types.AttributeValue) error {
+	items, err := b.registry.DecodeList(l)
+	if err != nil {
+		return err
+	}
+	if b.byType == nil {
+		b.byType = make(map[reflect.Type][]KeyedItem)
+	}
+	for _, v := range items {
+		typ := reflect.TypeOf(Untag(v))
+		b.byType[typ] = append(b.byType[typ], v)
+	}
+	b.items = append(b.items, items...)
+	return nil
+}
+
+// BundleItems returns the bundle's items of type T in query order;
+// T is the struct pointer of a tagged entity, e.g. BundleItems[*Profile]
+func BundleItems[T any](b *Bundle) []T {
+	l := b.byType[reflect.TypeFor[T]()]
+	out := make([]T, 0, len(l))
+	for _, v := range l {
+		out = append(out, Untag(v).(T))
+	}
+	return out
+}
+
+// BundleFirst returns the first item of type T, for single-instance entities such as a profile
+func BundleFirst[T any](b *Bundle) (T, bool) {
+	var zero T
+	l := b.byType[reflect.TypeFor[T]()]
+	if len(l) == 0 {
+		return zero, false
+	}
+	return Untag(l[0]).(T), true
+}
@@ -7,14 +7,27 @@ import (
 
 type Client struct {
 	client *dynamodb.Client
+	bus    *writeBus
+	opts   Options
 }
 
 func (c *Client) SDK() *dynamodb.Client { return c.client }
 
 func NewClient(awsCfg aws.Config, optFns ...func(*dynamodb.Options)) *Client {
-	cli := dynamodb.NewFromConfig(awsCfg, optFns...)
+	return NewClientWithOptions(awsCfg, WithSDKOptions(optFns...))
+}
+
+// NewClientWithOptions is NewClient with the Client-scoped Options, see WithSDKOptions for the SDK's
+func NewClientWithOptions(awsCfg aws.Config, optFns ...func(*Options)) *Client {
+	opts := defaultOptions()
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+	cli := dynamodb.NewFromConfig(awsCfg, opts.sdkOptions()...)
 	return &Client{
 		client: cli,
+		bus:    newWriteBus(),
+		opts:   opts,
 	}
 }
 
@@ -0,0 +1,93 @@
+package dynamox
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock is the time source of TimeNow, TimeDefaultNow and Model timestamps.
+// precedence: the context's (WithClock), the Client's (Options.Clock), the global (SetClock).
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SystemClock is time.Now
+var SystemClock Clock = systemClock{}
+
+type clockHolder struct{ Clock }
+
+var globalClock atomic.Pointer[clockHolder]
+
+func init() { SetClock(SystemClock) }
+
+// SetClock replaces the global clock, nil restores SystemClock
+func SetClock(clock Clock) {
+	if clock == nil {
+		clock = SystemClock
+	}
+	globalClock.Store(&clockHolder{clock})
+}
+
+func GlobalClock() Clock { return globalClock.Load().Clock }
+
+type clockCtxKey struct{}
+
+// WithClock returns a context whose operations read time from clock
+func WithClock(ctx context.Context, clock Clock) context.Context {
+	return context.WithValue(ctx, clockCtxKey{}, clock)
+}
+
+// ClockFrom returns the context's clock or the global one
+func ClockFrom(ctx context.Context) Clock {
+	if clock, ok := ctxClock(ctx); ok {
+		return clock
+	}
+	return GlobalClock()
+}
+
+func ctxClock(ctx context.Context) (Clock, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	clock, ok := ctx.Value(clockCtxKey{}).(Clock)
+	return clock, ok && clock != nil
+}
+
+/////////////////////////////////////////////////////////////////////////////
+
+// FakeClock is a manually driven Clock for tests
+//
+//	clock := dynamox.NewFakeClock(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+//	ctx := dynamox.WithClock(t.Context(), clock)
+//	clock.Advance(time.Hour)
+type FakeClock struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+func NewFakeClock(now time.Time) *FakeClock { return &FakeClock{now: now} }
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.now
+}
+
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+func (c *FakeClock) Advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	return c.now
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+var funcs = template.FuncMap{
+	"attrConst": func(s *structInfo, f *Field) string { return s.Name + "Attr" + f.Name },
+	"keyType":   func(s *structInfo) string { return "dynamoxKey" + upperFirst(s.Name) },
+	"keys": func(s *structInfo) []*key {
+		var keys []*key
+		for _, k := range []*key{s.PK, s.SK} {
+			if k != nil && (k.Prefix != "" || len(k.From) > 0) {
+				keys = append(keys, k)
+			}
+		}
+		return keys
+	},
+	"keyFields": func(s *structInfo) []*Field {
+		if s.SK == nil {
+			return []*Field{s.PK.Field}
+		}
+		return []*Field{s.PK.Field, s.SK.Field}
+	},
+	"source": func(f *Field) string {
+		switch f.Kind {
+		case kindString:
+			return "v." + f.Name
+		case kindInt:
+			return fmt.Sprintf("strconv.FormatInt(int64(v.%s), 10)", f.Name)
+		case kindUint:
+			return fmt.Sprintf("strconv.FormatUint(uint64(v.%s), 10)", f.Name)
+		default:
+			return fmt.Sprintf("fmt.Sprint(v.%s)", f.Name)
+		}
+	},
+	"pair": func(s *structInfo, f *Field) pairArg { return pairArg{s, f} },
+	"is": func(f *Field, k string) bool {
+		return map[string]kind{
+			"other": kindOther, "string": kindString, "bool": kindBool,
+			"int": kindInt, "uint": kindUint, "float": kindFloat, "bytes": kindBytes,
+		}[k] == f.Kind
+	},
+}
+
+var tmpl = template.Must(template.New("file").Funcs(funcs).Parse(`// Code generated by dynamox-gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-chujang/dynamox"
+)
+{{range $s := .Structs}}
+const (
+{{- range .Fields}}
+	{{attrConst $s .}} = {{printf "%q" .Attr}}
+{{- end}}
+)
+
+var (
+	_ dynamox.KeyedItem          = (*{{.Name}})(nil)
+	_ attributevalue.Marshaler   = (*{{.Name}})(nil)
+	_ attributevalue.Unmarshaler = (*{{.Name}})(nil)
+)
+
+func ({{.Name}}) Table() string   { return {{printf "%q" .Table}} }
+func ({{.Name}}) PKField() string { return {{attrConst $s .PK.Field}} }
+func ({{.Name}}) SKField() string { return {{if .SK}}{{attrConst $s .SK.Field}}{{else}}""{{end}} }
+func (v {{.Name}}) PK() any       { return v.{{.PK.Name}} }
+func (v {{.Name}}) SK() any       { return {{if .SK}}v.{{.SK.Name}}{{else}}nil{{end}} }
+
+func (v *{{.Name}}) GetKeyBase() dynamox.KeyBase { return {{keyType .}}{v} }
+
+func (v *{{.Name}}) SaveSK() error { return v.SaveSKWith(dynamox.GlobalConventions()) }
+
+func (v *{{.Name}}) SaveSKWith(cv dynamox.Conventions) error {
+{{- range keys .}}
+	if v.{{.Name}} == "" {
+		srcs := []string{ {{- range $i, $f := .From}}{{if $i}}, {{end}}{{source $f}}{{end -}} }
+		for _, src := range srcs {
+			if src == "" {
+				return fmt.Errorf("%w: %s", dynamox.ErrInsufficientCompositeKeySrc, {{attrConst $s .Field}})
+			}
+		}
+		v.{{.Name}} = cv.Composite({{printf "%q" .Prefix}}, srcs...)
+	}
+{{- end}}
+	return nil
+}
+
+func (v {{.Name}}) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	m := make(map[string]types.AttributeValue, {{len .Fields}})
+{{- range .Embedded}}
+	{{if .Pointer}}if v.{{.Name}} != nil {
+		{{end}}if av, err := attributevalue.Marshal(v.{{.Name}}); err != nil {
+		return nil, err
+	} else if em, ok := av.(*types.AttributeValueMemberM); ok {
+		maps.Copy(m, em.Value) // flattened, the fields of {{$s.Name}} below win
+	}{{if .Pointer}}
+	}{{end}}
+{{- end}}
+{{- range .Fields}}
+{{- template "marshal" (pair $s .)}}
+{{- end}}
+	return &types.AttributeValueMemberM{Value: m}, nil
+}
+
+func (v *{{.Name}}) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	m, ok := av.(*types.AttributeValueMemberM)
+	if !ok {
+		return dynamox.ErrExpectedMapAttribute
+	}
+{{- range .Embedded}}
+	if err := attributevalue.Unmarshal(m, &v.{{.Name}}); err != nil {
+		return err
+	}
+{{- end}}
+{{- range .Fields}}
+{{- template "unmarshal" (pair $s .)}}
+{{- end}}
+	return nil
+}
+
+// {{keyType .}} marshals only the key attributes of {{.Name}}, for MarshalMapOnlyKey
+type {{keyType .}} struct{ *{{.Name}} }
+
+func (k {{keyType .}}) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	v := k.{{.Name}}
+	m := make(map[string]types.AttributeValue, 2)
+{{- range keyFields .}}
+{{- template "marshal" (pair $s .)}}
+{{- end}}
+	return &types.AttributeValueMemberM{Value: m}, nil
+}
+{{end}}
+
+{{- define "marshal"}}{{$s := .S}}{{with .F}}
+{{- if is . "string"}}
+	{{if .OmitEmpty}}if v.{{.Name}} != "" {
+		m[{{attrConst $s .}}] = &types.AttributeValueMemberS{Value: v.{{.Name}}}
+	}{{else}}m[{{attrConst $s .}}] = &types.AttributeValueMemberS{Value: v.{{.Name}}}{{end}}
+{{- else if is . "bool"}}
+	{{if .OmitEmpty}}if v.{{.Name}} {
+		m[{{attrConst $s .}}] = &types.AttributeValueMemberBOOL{Value: v.{{.Name}}}
+	}{{else}}m[{{attrConst $s .}}] = &types.AttributeValueMemberBOOL{Value: v.{{.Name}}}{{end}}
+{{- else if or (is . "int") (is . "uint") (is . "float")}}
+	{{if .OmitEmpty}}if v.{{.Name}} != 0 {
+		{{end}}m[{{attrConst $s .}}] = &types.AttributeValueMemberN{Value: {{if is . "int"}}strconv.FormatInt(int64(v.{{.Name}}), 10){{else if is . "uint"}}strconv.FormatUint(uint64(v.{{.Name}}), 10){{else}}strconv.FormatFloat(float64(v.{{.Name}}), 'f', -1, {{.Bits}}){{end}}}
+	{{- if .OmitEmpty}}
+	}{{end}}
+{{- else if is . "bytes"}}
+	if v.{{.Name}} != nil {
+		m[{{attrConst $s .}}] = &types.AttributeValueMemberB{Value: v.{{.Name}}}
+	}{{if not .OmitEmpty}} else {
+		m[{{attrConst $s .}}] = &types.AttributeValueMemberNULL{Value: true}
+	}{{end}}
+{{- else}}
+	if av, err := attributevalue.Marshal(v.{{.Name}}); err != nil {
+		return nil, err
+	}{{if .OmitEmpty}} else if _, null := av.(*types.AttributeValueMemberNULL); !null {
+		m[{{attrConst $s .}}] = av
+	}{{else}} else {
+		m[{{attrConst $s .}}] = av
+	}{{end}}
+{{- end}}
+{{- end}}
+{{- end}}
+
+{{- define "unmarshal"}}{{$s := .S}}{{with .F}}
+{{- /* as attributevalue: an absent attribute leaves the field as is, NULL zeroes it */}}
+	switch av := m.Value[{{attrConst $s .}}].(type) {
+	case nil:
+{{- if or (is . "string") (is . "bool") (is . "int") (is . "uint") (is . "float") (is . "bytes")}}
+	case *types.AttributeValueMemberNULL:
+		v.{{.Name}} = {{if is . "string"}}""{{else if is . "bool"}}false{{else if is . "bytes"}}nil{{else}}0{{end}}
+{{- end}}
+{{- if is . "string"}}
+	case *types.AttributeValueMemberS:
+		v.{{.Name}} = av.Value
+	default:
+		return fmt.Errorf("%w: %s", dynamox.ErrExpectedStringAttribute, {{attrConst $s .}})
+{{- else if is . "bool"}}
+	case *types.AttributeValueMemberBOOL:
+		v.{{.Name}} = av.Value
+	default:
+		return fmt.Errorf("%w: %s %T", dynamox.ErrUnexpectedAttributeType, {{attrConst $s .}}, av)
+{{- else if or (is . "int") (is . "uint") (is . "float")}}
+	case *types.AttributeValueMemberN:
+		n, err := {{if is . "int"}}strconv.ParseInt(av.Value, 10, {{.Bits}}){{else if is . "uint"}}strconv.ParseUint(av.Value, 10, {{.Bits}}){{else}}strconv.ParseFloat(av.Value, {{.Bits}}){{end}}
+		if err != nil {
+			return fmt.Errorf("%s: %w", {{attrConst $s .}}, err)
+		}
+		v.{{.Name}} = {{.GoType}}(n)
+	default:
+		return fmt.Errorf("%w: %s", dynamox.ErrExpectedNumberAttribute, {{attrConst $s .}})
+{{- else if is . "bytes"}}
+	case *types.AttributeValueMemberB:
+		v.{{.Name}} = av.Value
+	default:
+		return fmt.Errorf("%w: %s %T", dynamox.ErrUnexpectedAttributeType, {{attrConst $s .}}, av)
+{{- else}}
+	default:
+		if err := attributevalue.Unmarshal(av, &v.{{.Name}}); err != nil {
+			return err
+		}
+{{- end}}
+	}
+{{- end}}
+{{- end}}
+`))
+
+type pairArg struct {
+	S *structInfo
+	F *Field
+}
+
+func generate(pkg string, structs []*structInfo) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]any{"Package": pkg, "Structs": structs}); err != nil {
+		return nil, err
+	}
+	src := dropUnusedImports(buf.String())
+	out, err := format.Source([]byte(src))
+	if err != nil {
+		return nil, fmt.Errorf("format generated source: %w\n%s", err, src)
+	}
+	return out, nil
+}
+
+// dropUnusedImports removes the optional imports the generated body does not reference
+func dropUnusedImports(src string) string {
+	head, body, _ := strings.Cut(src, "\n)\n")
+	for pkg, line := range map[string]string{
+		"fmt.":     "\t\"fmt\"\n",
+		"maps.":    "\t\"maps\"\n",
+		"strconv.": "\t\"strconv\"\n",
+	} {
+		if !strings.Contains(body, pkg) {
+			head = strings.Replace(head, line, "", 1)
+		}
+	}
+	return head + "\n)\n" + body
+}
+
+func upperFirst(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
@@ -0,0 +1,76 @@
+// dynamox-gen generates KeyBase / KeyedItem methods for structs tagged with
+// `dynamox` keys, as a zero-reflection alternative to dynamox.Tagged.
+//
+//	//go:generate go run github.com/go-chujang/dynamox/cmd/dynamox-gen
+//
+//	//dynamox:table CustomerBookmark
+//	type Profile struct {
+//		CustomerId string `dynamodbav:"customerId" dynamox:"pk"`
+//		Sk         string `dynamodbav:"sk" dynamox:"sk,prefix=CUST,from=CustomerId"`
+//		Email      string `dynamodbav:"email"`
+//	}
+//
+// for each struct of the file ($GOFILE) having a `dynamox:"pk"` field, emits into <file>_dynamox.go:
+//   - attribute-name constants, e.g. ProfileAttrEmail
+//   - Table, PKField, SKField, PK, SK, GetKeyBase
+//   - SaveSK composing empty keys by SortKeyPrefix.Composite
+//   - MarshalDynamoDBAttributeValue / UnmarshalDynamoDBAttributeValue without attributevalue reflection
+//     for string, bool, numeric and []byte fields, the same attributes as attributevalue;
+//     embedded structs such as dynamox.Model are flattened through attributevalue
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		if !errors.Is(err, errUsage) && !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "dynamox-gen:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("dynamox-gen", flag.ContinueOnError)
+	var (
+		file   = fs.String("file", os.Getenv("GOFILE"), "source file, $GOFILE under go generate")
+		output = fs.String("output", "", "output file (default <file>_dynamox.go)")
+		only   = fs.String("type", "", "comma-separated struct names (default all tagged structs)")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		fs.Usage()
+		return errUsage
+	}
+	var names []string
+	if *only != "" {
+		names = strings.Split(*only, ",")
+	}
+
+	pkg, structs, err := parseFile(*file, names)
+	if err != nil {
+		return err
+	}
+	if len(structs) == 0 {
+		return fmt.Errorf("%s: no struct with a `dynamox:\"pk\"` field", *file)
+	}
+	src, err := generate(pkg, structs)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		*output = strings.TrimSuffix(*file, filepath.Ext(*file)) + "_dynamox.go"
+	}
+	return os.WriteFile(*output, src, 0o644)
+}
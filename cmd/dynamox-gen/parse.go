@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// kind of a field, deciding how it is marshaled
+type kind int
+
+const (
+	kindOther kind = iota // through attributevalue
+	kindString
+	kindBool
+	kindInt
+	kindUint
+	kindFloat
+	kindBytes
+)
+
+type Field struct {
+	Name      string
+	Attr      string
+	GoType    string
+	Kind      kind
+	OmitEmpty bool
+	Bits      int // for numeric kinds
+}
+
+type key struct {
+	*Field
+	Prefix string
+	From   []*Field
+}
+
+// embedded struct, flattened through attributevalue as it does
+type embedded struct {
+	Name    string
+	Pointer bool
+}
+
+type structInfo struct {
+	Name     string
+	Table    string
+	Fields   []*Field
+	Embedded []*embedded
+	PK       *key
+	SK       *key
+}
+
+const tableDirective = "//dynamox:table "
+
+func parseFile(path string, names []string) (string, []*structInfo, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		return "", nil, err
+	}
+	var structs []*structInfo
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok || ts.TypeParams != nil || (len(names) > 0 && !slices.Contains(names, ts.Name.Name)) {
+				continue
+			}
+			doc := ts.Doc
+			if doc == nil && len(gen.Specs) == 1 {
+				doc = gen.Doc
+			}
+			info, err := parseStruct(ts.Name.Name, st, doc)
+			if err != nil {
+				return "", nil, fmt.Errorf("%s: %w", fset.Position(ts.Pos()), err)
+			}
+			if info != nil {
+				structs = append(structs, info)
+			}
+		}
+	}
+	return f.Name.Name, structs, nil
+}
+
+// parseStruct returns nil for structs without dynamox tags
+func parseStruct(name string, st *ast.StructType, doc *ast.CommentGroup) (*structInfo, error) {
+	info := &structInfo{Name: name}
+	if doc != nil {
+		for _, c := range doc.List {
+			if table, ok := strings.CutPrefix(c.Text, tableDirective); ok {
+				info.Table = strings.TrimSpace(table)
+			}
+		}
+	}
+
+	var (
+		tagged   bool
+		fromRefs = map[*key][]string{}
+	)
+	for _, f := range st.Fields.List {
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			s, _ := strconv.Unquote(f.Tag.Value)
+			tag = reflect.StructTag(s)
+		}
+		dtag, hasKey := tag.Lookup("dynamox")
+		av, opts, _ := strings.Cut(tag.Get("dynamodbav"), ",")
+		if av == "-" {
+			continue
+		}
+		names := f.Names
+		if len(names) == 0 {
+			if hasKey {
+				return nil, fmt.Errorf("%s: embedded key fields are not supported", name)
+			}
+			ident, pointer := embeddedName(f.Type)
+			if ident == nil {
+				return nil, fmt.Errorf("%s: unsupported embedded type %s", name, exprString(f.Type))
+			}
+			if av == "" {
+				info.Embedded = append(info.Embedded, &embedded{Name: ident.Name, Pointer: pointer})
+				continue
+			}
+			names = []*ast.Ident{ident} // named by the tag, an attribute of its own
+		}
+		for _, n := range names {
+			if !n.IsExported() {
+				continue
+			}
+			fd := &Field{Name: n.Name, Attr: av, GoType: exprString(f.Type), OmitEmpty: strings.Contains(opts, "omitempty")}
+			if fd.Attr == "" {
+				fd.Attr = n.Name
+			}
+			fd.Kind, fd.Bits = kindOf(f.Type)
+			info.Fields = append(info.Fields, fd)
+			if !hasKey {
+				continue
+			}
+			tagged = true
+			parts := strings.Split(dtag, ",")
+			k := &key{Field: fd}
+			for _, opt := range parts[1:] {
+				name, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+				switch name {
+				case "prefix":
+					k.Prefix = value
+				case "from":
+					fromRefs[k] = strings.Split(value, "+")
+				default:
+					return nil, fmt.Errorf("%s.%s: unknown option %q", info.Name, fd.Name, name)
+				}
+			}
+			switch strings.TrimSpace(parts[0]) {
+			case "pk":
+				if info.PK != nil {
+					return nil, fmt.Errorf("%s: multiple pk", info.Name)
+				}
+				info.PK = k
+			case "sk":
+				if info.SK != nil {
+					return nil, fmt.Errorf("%s: multiple sk", info.Name)
+				}
+				info.SK = k
+			default:
+				return nil, fmt.Errorf("%s.%s: unknown key %q", info.Name, fd.Name, parts[0])
+			}
+		}
+	}
+	if !tagged {
+		return nil, nil
+	}
+	if info.PK == nil {
+		return nil, fmt.Errorf("%s: no pk", info.Name)
+	}
+	for k, refs := range fromRefs {
+		if k.Kind != kindString || k.GoType != "string" {
+			return nil, fmt.Errorf("%s.%s: composite key must be string", info.Name, k.Name)
+		}
+		for _, ref := range refs {
+			i := slices.IndexFunc(info.Fields, func(f *Field) bool { return f.Name == ref })
+			if i < 0 {
+				return nil, fmt.Errorf("%s: no field %q", info.Name, ref)
+			}
+			k.From = append(k.From, info.Fields[i])
+		}
+	}
+	return info, nil
+}
+
+// embeddedName is the field name of an embedded T, *T or pkg.T
+func embeddedName(expr ast.Expr) (*ast.Ident, bool) {
+	star, pointer := expr.(*ast.StarExpr)
+	if pointer {
+		expr = star.X
+	}
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		return sel.Sel, pointer
+	}
+	ident, _ := expr.(*ast.Ident)
+	return ident, pointer
+}
+
+func kindOf(expr ast.Expr) (kind, int) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return kindString, 0
+		case "bool":
+			return kindBool, 0
+		case "int", "int64":
+			return kindInt, 64
+		case "int8", "int16", "int32":
+			n, _ := strconv.Atoi(strings.TrimPrefix(t.Name, "int"))
+			return kindInt, n
+		case "uint", "uint64":
+			return kindUint, 64
+		case "uint8", "uint16", "uint32":
+			n, _ := strconv.Atoi(strings.TrimPrefix(t.Name, "uint"))
+			return kindUint, n
+		case "float32":
+			return kindFloat, 32
+		case "float64":
+			return kindFloat, 64
+		}
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && t.Len == nil && ident.Name == "byte" {
+			return kindBytes, 0
+		}
+	}
+	return kindOther, 0
+}
+
+func exprString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return exprString(t.X) + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + exprString(t.X)
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "[]" + exprString(t.Elt)
+		}
+	case *ast.MapType:
+		return "map[" + exprString(t.Key) + "]" + exprString(t.Value)
+	}
+	return "any"
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// item as it comes back from DynamoDB, without any lossy conversion
+type rawItem map[string]types.AttributeValue
+
+var _ attributevalue.Unmarshaler = (*rawItem)(nil)
+
+func (r *rawItem) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	m, ok := av.(*types.AttributeValueMemberM)
+	if !ok {
+		return fmt.Errorf("expected M attribute, got %T", av)
+	}
+	*r = m.Value
+	return nil
+}
+
+// plain returns the item as JSON-friendly go values; numbers are kept as json.Number
+func (r rawItem) plain() (map[string]any, error) {
+	out := make(map[string]any, len(r))
+	err := attributevalue.UnmarshalMapWithOptions(r, &out, func(o *attributevalue.DecoderOptions) {
+		o.UseNumber = true
+	})
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range out {
+		out[k] = plainNumbers(v)
+	}
+	return out, nil
+}
+
+func plainNumbers(v any) any {
+	switch vv := v.(type) {
+	case attributevalue.Number:
+		return json.Number(vv)
+	case []attributevalue.Number:
+		l := make([]json.Number, len(vv))
+		for i := range vv {
+			l[i] = json.Number(vv[i])
+		}
+		return l
+	case []any:
+		for i := range vv {
+			vv[i] = plainNumbers(vv[i])
+		}
+	case map[string]any:
+		for k := range vv {
+			vv[k] = plainNumbers(vv[k])
+		}
+	}
+	return v
+}
+
+// parsePlainItem decodes plain JSON ({"id":"a","n":1}) into an item
+func parsePlainItem(data []byte) (map[string]types.AttributeValue, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var m map[string]any
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	if len(m) == 0 {
+		return nil, fmt.Errorf("empty item")
+	}
+	return attributevalue.MarshalMap(m)
+}
+
+// parseScalar interprets a command-line value as JSON when possible,
+// falling back to the raw string ("123" -> number, "\"123\"" -> string, abc -> string)
+func parseScalar(s string) any {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return s
+	}
+	return v
+}
+
+/////////////////////////////////////////////////////////////////////////////
+// DynamoDB JSON ({"id":{"S":"a"},"n":{"N":"1"}}), used by dump and load
+
+func encodeDynamoJSON(item map[string]types.AttributeValue) ([]byte, error) {
+	m := make(map[string]any, len(item))
+	for k, v := range item {
+		enc, err := avToDynamoJSON(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		m[k] = enc
+	}
+	return json.Marshal(m)
+}
+
+func avToDynamoJSON(av types.AttributeValue) (map[string]any, error) {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return map[string]any{"S": v.Value}, nil
+	case *types.AttributeValueMemberN:
+		return map[string]any{"N": v.Value}, nil
+	case *types.AttributeValueMemberB:
+		return map[string]any{"B": base64.StdEncoding.EncodeToString(v.Value)}, nil
+	case *types.AttributeValueMemberBOOL:
+		return map[string]any{"BOOL": v.Value}, nil
+	case *types.AttributeValueMemberNULL:
+		return map[string]any{"NULL": true}, nil
+	case *types.AttributeValueMemberSS:
+		return map[string]any{"SS": v.Value}, nil
+	case *types.AttributeValueMemberNS:
+		return map[string]any{"NS": v.Value}, nil
+	case *types.AttributeValueMemberBS:
+		l := make([]string, len(v.Value))
+		for i := range v.Value {
+			l[i] = base64.StdEncoding.EncodeToString(v.Value[i])
+		}
+		return map[string]any{"BS": l}, nil
+	case *types.AttributeValueMemberL:
+		l := make([]any, len(v.Value))
+		for i := range v.Value {
+			enc, err := avToDynamoJSON(v.Value[i])
+			if err != nil {
+				return nil, err
+			}
+			l[i] = enc
+		}
+		return map[string]any{"L": l}, nil
+	case *types.AttributeValueMemberM:
+		m := make(map[string]any, len(v.Value))
+		for k := range v.Value {
+			enc, err := avToDynamoJSON(v.Value[k])
+			if err != nil {
+				return nil, err
+			}
+			m[k] = enc
+		}
+		return map[string]any{"M": m}, nil
+	default:
+		return nil, fmt.Errorf("unsupported attribute value %T", av)
+	}
+}
+
+func decodeDynamoJSON(data []byte) (map[string]types.AttributeValue, error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	item := make(map[string]types.AttributeValue, len(m))
+	for k, v := range m {
+		av, err := dynamoJSONToAV(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		item[k] = av
+	}
+	return item, nil
+}
+
+func dynamoJSONToAV(data json.RawMessage) (types.AttributeValue, error) {
+	var typed map[string]json.RawMessage
+	if err := json.Unmarshal(data, &typed); err != nil {
+		return nil, err
+	}
+	if len(typed) != 1 {
+		return nil, fmt.Errorf("expected exactly one type descriptor, got %d", len(typed))
+	}
+	for typ, raw := range typed {
+		switch typ {
+		case "S":
+			var v string
+			err := json.Unmarshal(raw, &v)
+			return &types.AttributeValueMemberS{Value: v}, err
+		case "N":
+			var v string
+			err := json.Unmarshal(raw, &v)
+			return &types.AttributeValueMemberN{Value: v}, err
+		case "B":
+			var v []byte // base64 by encoding/json
+			err := json.Unmarshal(raw, &v)
+			return &types.AttributeValueMemberB{Value: v}, err
+		case "BOOL":
+			var v bool
+			err := json.Unmarshal(raw, &v)
+			return &types.AttributeValueMemberBOOL{Value: v}, err
+		case "NULL":
+			return &types.AttributeValueMemberNULL{Value: true}, nil
+		case "SS":
+			var v []string
+			err := json.Unmarshal(raw, &v)
+			return &types.AttributeValueMemberSS{Value: v}, err
+		case "NS":
+			var v []string
+			err := json.Unmarshal(raw, &v)
+			return &types.AttributeValueMemberNS{Value: v}, err
+		case "BS":
+			var v [][]byte
+			err := json.Unmarshal(raw, &v)
+			return &types.AttributeValueMemberBS{Value: v}, err
+		case "L":
+			var l []json.RawMessage
+			if err := json.Unmarshal(raw, &l); err != nil {
+				return nil, err
+			}
+			out := make([]types.AttributeValue, len(l))
+			for i := range l {
+				av, err := dynamoJSONToAV(l[i])
+				if err != nil {
+					return nil, err
+				}
+				out[i] = av
+			}
+			return &types.AttributeValueMemberL{Value: out}, nil
+		case "M":
+			var m map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &m); err != nil {
+				return nil, err
+			}
+			out := make(map[string]types.AttributeValue, len(m))
+			for k := range m {
+				av, err := dynamoJSONToAV(m[k])
+				if err != nil {
+					return nil, err
+				}
+				out[k] = av
+			}
+			return &types.AttributeValueMemberM{Value: out}, nil
+		default:
+			return nil, fmt.Errorf("unsupported type descriptor %q", typ)
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,414 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-chujang/dynamox"
+)
+
+func (a *app) get(ctx context.Context, args []string) error {
+	var (
+		fs         = a.flagSet("get")
+		table      = fs.String("table", "", "table name")
+		keyJSON    = fs.String("key", "", `item key as JSON, e.g. {"pk":"a","sk":"b"}`)
+		consistent = fs.Bool("consistent", false, "strongly consistent read")
+		proj       = fs.String("proj", "", "comma separated attributes to return")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireTable(*table); err != nil {
+		return err
+	}
+	key, err := parsePlainItem([]byte(*keyJSON))
+	if err != nil {
+		return fmt.Errorf("-key: %w", err)
+	}
+
+	query := dynamox.NewCtxQuery(ctx).SimpleGet(*table, key, *consistent)
+	if *proj != "" {
+		expr, err := projectionExpr(*proj)
+		if err != nil {
+			return err
+		}
+		query.ExprGet(expr).SetProjectExpr(expr.Projection())
+	}
+	var item rawItem
+	if err = a.cli.Get(query, &item); err != nil {
+		return err
+	}
+	return a.printPage(1, []rawItem{item}, nil, keyNames(key))
+}
+
+func (a *app) query(ctx context.Context, args []string) error {
+	var (
+		fs         = a.flagSet("query")
+		table      = fs.String("table", "", "table name")
+		index      = fs.String("index", "", "index name")
+		pk         = fs.String("pk", "", "partition key value")
+		sk         = fs.String("sk", "", "sort key value")
+		skOp       = fs.String("sk-op", "eq", "sort key operator: eq | lt | le | gt | ge | begins_with | between")
+		sk2        = fs.String("sk2", "", "upper sort key value for between")
+		proj       = fs.String("proj", "", "comma separated attributes to return")
+		desc       = fs.Bool("desc", false, "descending sort key order")
+		pf         = newPageFlags(fs)
+		consistent = fs.Bool("consistent", false, "strongly consistent read (not on GSI)")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireTable(*table); err != nil {
+		return err
+	}
+	if *pk == "" {
+		return fmt.Errorf("%w: -pk is required", errUsage)
+	}
+	if *sk == "" && (flagGiven(fs, "sk-op") || *sk2 != "") {
+		return fmt.Errorf("%w: -sk-op and -sk2 need -sk", errUsage)
+	}
+
+	tableDesc, err := describeTable(ctx, a.cli, *table)
+	if err != nil {
+		return err
+	}
+	pkField, skField, err := queryKeyFields(tableDesc, *index)
+	if err != nil {
+		return err
+	}
+	pkValue, err := typedKeyValue(tableDesc, pkField, *pk)
+	if err != nil {
+		return err
+	}
+
+	kcb := dynamox.NewKeyCondBuilder().WithPK(pkField, pkValue)
+	if *sk != "" {
+		if skField == "" {
+			return fmt.Errorf("%w: -sk given but key schema has no sort key", errUsage)
+		}
+		op, err := dynamox.ParseConditionOperator(*skOp)
+		if err != nil {
+			return fmt.Errorf("-sk-op: %w", err)
+		}
+		skValue, err := typedKeyValue(tableDesc, skField, *sk)
+		if err != nil {
+			return err
+		}
+		var upper []any
+		if *sk2 != "" {
+			skUpper, err := typedKeyValue(tableDesc, skField, *sk2)
+			if err != nil {
+				return err
+			}
+			upper = append(upper, skUpper)
+		}
+		kcb.WithSK(op, skField, skValue, upper...)
+	}
+	if *proj != "" {
+		kcb.WithProj(strings.Split(*proj, ",")...)
+	}
+
+	query := dynamox.NewCtxQuery(ctx).
+		SetTable(*table).
+		SetIndex(*index).
+		SetKeyCondBuilder(kcb).
+		SetOrderByAsc(!*desc).
+		SetConsistentRead(*consistent)
+	if err = pf.apply(query); err != nil {
+		return err
+	}
+	var items []rawItem
+	count, next, err := a.cli.Query(query, &items)
+	if err != nil {
+		return err
+	}
+	return a.printPage(count, items, next, nonEmpty(pkField, skField))
+}
+
+func (a *app) scan(ctx context.Context, args []string) error {
+	var (
+		fs         = a.flagSet("scan")
+		table      = fs.String("table", "", "table name")
+		index      = fs.String("index", "", "index name")
+		proj       = fs.String("proj", "", "comma separated attributes to return")
+		pf         = newPageFlags(fs)
+		consistent = fs.Bool("consistent", false, "strongly consistent read (not on GSI)")
+		filters    multiFlag
+	)
+	fs.Var(&filters, "filter", "field:op[:value], op: eq ne lt le gt ge begins_with contains exists not_exists (repeatable, AND)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireTable(*table); err != nil {
+		return err
+	}
+
+	query := dynamox.NewCtxQuery(ctx).SetTable(*table).SetIndex(*index).SetConsistentRead(*consistent)
+	if len(filters) > 0 || *proj != "" {
+		builder := expression.NewBuilder()
+		if len(filters) > 0 {
+			cond, err := parseFilters(filters)
+			if err != nil {
+				return err
+			}
+			builder = builder.WithFilter(cond)
+		}
+		if *proj != "" {
+			builder = builder.WithProjection(projectionBuilder(*proj))
+		}
+		expr, err := builder.Build()
+		if err != nil {
+			return err
+		}
+		query.ExprScan(expr)
+	}
+	if err := pf.apply(query); err != nil {
+		return err
+	}
+	var items []rawItem
+	count, next, err := a.cli.Scan(query, &items)
+	if err != nil {
+		return err
+	}
+	return a.printPage(count, items, next, nil)
+}
+
+func (a *app) put(ctx context.Context, args []string) error {
+	var (
+		fs       = a.flagSet("put")
+		table    = fs.String("table", "", "table name")
+		itemJSON = fs.String("item", "", "item as plain JSON")
+		file     = fs.String("file", "", "read item JSON from file, - for stdin")
+		ddbJSON  = fs.Bool("ddb-json", false, "input is DynamoDB JSON ({\"id\":{\"S\":\"a\"}})")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireTable(*table); err != nil {
+		return err
+	}
+
+	data := []byte(*itemJSON)
+	if *file != "" {
+		var err error
+		if data, err = a.readFile(*file); err != nil {
+			return err
+		}
+	}
+	parse := parsePlainItem
+	if *ddbJSON {
+		parse = decodeDynamoJSON
+	}
+	item, err := parse(data)
+	if err != nil {
+		return fmt.Errorf("item: %w", err)
+	}
+	if err = a.cli.Put(dynamox.NewCtxQuery(ctx).SimplePut(*table, item)); err != nil {
+		return err
+	}
+	return a.printPage(1, []rawItem{item}, nil, nil)
+}
+
+func (a *app) delete(ctx context.Context, args []string) error {
+	var (
+		fs      = a.flagSet("delete")
+		table   = fs.String("table", "", "table name")
+		keyJSON = fs.String("key", "", `item key as JSON, e.g. {"pk":"a","sk":"b"}`)
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireTable(*table); err != nil {
+		return err
+	}
+	key, err := parsePlainItem([]byte(*keyJSON))
+	if err != nil {
+		return fmt.Errorf("-key: %w", err)
+	}
+
+	var old rawItem
+	query := dynamox.NewCtxQuery(ctx).SimpleDelete(*table, key).SetReturnValues(types.ReturnValueAllOld)
+	if err = a.cli.Delete(query, &old); err != nil {
+		return err
+	}
+	if len(old) == 0 {
+		return dynamox.ErrNotFoundItem
+	}
+	return a.printPage(1, []rawItem{old}, nil, keyNames(key))
+}
+
+/////////////////////////////////////////////////////////////////////////////
+
+type pageFlags struct {
+	limit *int
+	start *string
+}
+
+func newPageFlags(fs *flag.FlagSet) pageFlags {
+	return pageFlags{
+		limit: fs.Int("limit", 0, "maximum number of items to evaluate"),
+		start: fs.String("start", "", "pagination token from a previous next"),
+	}
+}
+
+func (pf pageFlags) apply(query *dynamox.CtxQuery) error {
+	if *pf.limit > 0 {
+		query.SetLimit(int32(*pf.limit))
+	}
+	if *pf.start != "" {
+		var pgk dynamox.PaginationKey
+		start, err := pgk.Import(*pf.start)
+		if err != nil {
+			return fmt.Errorf("-start: %w", err)
+		}
+		query.SetStartKey(start)
+	}
+	return nil
+}
+
+func (a *app) readFile(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(a.stdin)
+	}
+	return os.ReadFile(path)
+}
+
+func queryKeyFields(desc *types.TableDescription, index string) (pk, sk string, err error) {
+	if index == "" {
+		pk, sk = keySchemaFields(desc.KeySchema)
+		return pk, sk, nil
+	}
+	for _, v := range desc.GlobalSecondaryIndexes {
+		if aws.ToString(v.IndexName) == index {
+			pk, sk = keySchemaFields(v.KeySchema)
+			return pk, sk, nil
+		}
+	}
+	for _, v := range desc.LocalSecondaryIndexes {
+		if aws.ToString(v.IndexName) == index {
+			pk, sk = keySchemaFields(v.KeySchema)
+			return pk, sk, nil
+		}
+	}
+	return "", "", fmt.Errorf("%w: unknown index %q on %s", errUsage, index, aws.ToString(desc.TableName))
+}
+
+// typedKeyValue converts a command-line value to the key attribute's declared type
+func typedKeyValue(desc *types.TableDescription, field, value string) (any, error) {
+	for _, v := range desc.AttributeDefinitions {
+		if aws.ToString(v.AttributeName) != field {
+			continue
+		}
+		switch v.AttributeType {
+		case types.ScalarAttributeTypeN:
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return nil, fmt.Errorf("%s: %w", field, dynamox.ErrExpectedNumberAttribute)
+			}
+			return json.Number(value), nil
+		case types.ScalarAttributeTypeB:
+			return base64.StdEncoding.DecodeString(value)
+		default:
+			return value, nil
+		}
+	}
+	return value, nil
+}
+
+func parseFilters(filters []string) (expression.ConditionBuilder, error) {
+	var conds []expression.ConditionBuilder
+	for _, v := range filters {
+		cond, err := parseFilter(v)
+		if err != nil {
+			return expression.ConditionBuilder{}, fmt.Errorf("-filter %q: %w", v, err)
+		}
+		conds = append(conds, cond)
+	}
+	if len(conds) == 1 {
+		return conds[0], nil
+	}
+	return expression.And(conds[0], conds[1], conds[2:]...), nil
+}
+
+func parseFilter(s string) (expression.ConditionBuilder, error) {
+	split := strings.SplitN(s, ":", 3)
+	if len(split) < 2 || split[0] == "" {
+		return expression.ConditionBuilder{}, errUsage
+	}
+	name := expression.Name(split[0])
+	op := strings.ToLower(split[1])
+	switch op {
+	case "exists":
+		return name.AttributeExists(), nil
+	case "not_exists":
+		return name.AttributeNotExists(), nil
+	}
+	if len(split) != 3 {
+		return expression.ConditionBuilder{}, fmt.Errorf("%w: %s requires a value", errUsage, op)
+	}
+	raw := split[2]
+	value := expression.Value(parseScalar(raw))
+	switch op {
+	case "eq", "=":
+		return name.Equal(value), nil
+	case "ne", "<>", "!=":
+		return name.NotEqual(value), nil
+	case "lt", "<":
+		return name.LessThan(value), nil
+	case "le", "<=":
+		return name.LessThanEqual(value), nil
+	case "gt", ">":
+		return name.GreaterThan(value), nil
+	case "ge", ">=":
+		return name.GreaterThanEqual(value), nil
+	case "begins_with":
+		return name.BeginsWith(raw), nil
+	case "contains":
+		return name.Contains(raw), nil
+	default:
+		return expression.ConditionBuilder{}, fmt.Errorf("%w: %s", dynamox.ErrUnexpectedConditionOperator, op)
+	}
+}
+
+func projectionBuilder(proj string) expression.ProjectionBuilder {
+	var builder expression.ProjectionBuilder
+	for _, v := range strings.Split(proj, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			builder = builder.AddNames(expression.Name(v))
+		}
+	}
+	return builder
+}
+
+func projectionExpr(proj string) (expression.Expression, error) {
+	return expression.NewBuilder().WithProjection(projectionBuilder(proj)).Build()
+}
+
+func keyNames(key map[string]types.AttributeValue) []string {
+	names := make([]string, 0, len(key))
+	for k := range key {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func nonEmpty(s ...string) []string {
+	out := make([]string, 0, len(s))
+	for _, v := range s {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
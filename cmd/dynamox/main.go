@@ -0,0 +1,161 @@
+// dynamox is a small command-line tool for day-to-day work against DynamoDB
+// (or DynamoDB Local) built on top of dynamox.Client.
+//
+//	dynamox [global flags] <command> [flags]
+//
+//	tables ls | describe | create | drop
+//	get | query | scan | put | delete
+//	dump | load
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/go-chujang/dynamox"
+)
+
+const usage = `usage: dynamox [global flags] <command> [flags]
+
+commands:
+  tables ls                       list tables
+  tables describe -table T        describe table, keys and indexes
+  tables create -table T -pk name:S [-sk name:S] [-gsi pk:S[,sk:S]]... [-lsi sk:S]...
+  tables drop -table T -yes       delete table
+  get     -table T -key JSON      get an item by its key
+  query   -table T -pk VALUE [-sk-op OP -sk VALUE [-sk2 VALUE]] [-index NAME]
+  scan    -table T [-filter field:op[:value]]...
+  put     -table T [-item JSON | -file PATH]
+  delete  -table T -key JSON
+  dump    -table T [-file PATH]   write every item as DynamoDB JSON lines
+  load    -table T [-file PATH]   batch-write DynamoDB JSON lines
+
+global flags:
+`
+
+var errUsage = errors.New("invalid usage")
+
+type app struct {
+	cli    *dynamox.Client
+	format string
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, errUsage) && !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "dynamox:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	var (
+		fs       = flag.NewFlagSet("dynamox", flag.ContinueOnError)
+		endpoint = fs.String("endpoint", os.Getenv("DYNAMOX_ENDPOINT"), "endpoint url, e.g. http://localhost:8000 for DynamoDB Local")
+		region   = fs.String("region", "", "aws region (default from environment)")
+		profile  = fs.String("profile", "", "shared config profile")
+		local    = fs.Bool("local", false, "use static dummy credentials, for DynamoDB Local")
+		format   = fs.String("o", "json", "output format: json | table")
+	)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+	if *format != "json" && *format != "table" {
+		return fmt.Errorf("unexpected output format: %s", *format)
+	}
+
+	var loadOpts []func(*config.LoadOptions) error
+	if *region != "" {
+		loadOpts = append(loadOpts, config.WithRegion(*region))
+	}
+	if *profile != "" {
+		loadOpts = append(loadOpts, config.WithSharedConfigProfile(*profile))
+	}
+	if *endpoint != "" {
+		loadOpts = append(loadOpts, config.WithBaseEndpoint(*endpoint))
+	}
+	if *local {
+		loadOpts = append(loadOpts, config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider("local", "local", "")))
+	}
+	awsCfg, err := config.LoadDefaultConfig(ctx, loadOpts...)
+	if err != nil {
+		return err
+	}
+	if awsCfg.Region == "" {
+		awsCfg.Region = "us-east-1"
+	}
+	a := &app{
+		cli:    dynamox.NewClient(awsCfg),
+		format: *format,
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+	}
+
+	cmd, rest := fs.Arg(0), fs.Args()[1:]
+	switch cmd {
+	case "tables":
+		return a.tables(ctx, rest)
+	case "get":
+		return a.get(ctx, rest)
+	case "query":
+		return a.query(ctx, rest)
+	case "scan":
+		return a.scan(ctx, rest)
+	case "put":
+		return a.put(ctx, rest)
+	case "delete":
+		return a.delete(ctx, rest)
+	case "dump":
+		return a.dump(ctx, rest)
+	case "load":
+		return a.load(ctx, rest)
+	default:
+		fs.Usage()
+		return fmt.Errorf("%w: unknown command %q", errUsage, cmd)
+	}
+}
+
+func (a *app) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("dynamox "+name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	return fs
+}
+
+// flagGiven reports whether name was set on the command line, not left to its default
+func flagGiven(fs *flag.FlagSet, name string) (given bool) {
+	fs.Visit(func(f *flag.Flag) { given = given || f.Name == name })
+	return given
+}
+
+func requireTable(table string) error {
+	if table == "" {
+		return fmt.Errorf("%w: -table is required", errUsage)
+	}
+	return nil
+}
+
+// repeatable string flag
+type multiFlag []string
+
+func (m *multiFlag) String() string     { return fmt.Sprint(*m) }
+func (m *multiFlag) Set(v string) error { *m = append(*m, v); return nil }
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// scanEndpoint answers every request with one page of items
+func scanEndpoint(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if target := r.Header.Get("X-Amz-Target"); target != "DynamoDB_20120810.Scan" {
+			t.Errorf("unexpected request %s", target)
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		io.WriteString(w, `{"Count":2,"ScannedCount":2,"Items":[{"pk":{"S":"a"}},{"pk":{"S":"b"},"n":{"N":"1"}}]}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// describeEndpoint answers DescribeTable with a table of no secondary index, and fails a Scan
+// after its first page
+func describeEndpoint(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		body, _ := io.ReadAll(r.Body)
+		switch target := r.Header.Get("X-Amz-Target"); {
+		case target == "DynamoDB_20120810.DescribeTable":
+			io.WriteString(w, `{"Table":{"TableName":"T","KeySchema":[{"AttributeName":"pk","KeyType":"HASH"}],
+				"AttributeDefinitions":[{"AttributeName":"pk","AttributeType":"S"}]}}`)
+		case target == "DynamoDB_20120810.Scan" && !bytes.Contains(body, []byte("ExclusiveStartKey")):
+			io.WriteString(w, `{"Count":1,"Items":[{"pk":{"S":"a"}}],"LastEvaluatedKey":{"pk":{"S":"a"}}}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"__type":"com.amazon.coral.validate#ValidationException","message":"broken"}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func Test_run(t *testing.T) {
+	var (
+		ctx    = t.Context()
+		global = []string{"-endpoint", scanEndpoint(t), "-region", "us-east-1", "-local"}
+		stderr bytes.Buffer
+	)
+	if err := run(ctx, nil, nil, io.Discard, &stderr); !errors.Is(err, errUsage) || !strings.Contains(stderr.String(), "usage:") {
+		t.Fatalf("expected usage, got %v", err)
+	}
+	if err := run(ctx, append(global, "nope"), nil, io.Discard, io.Discard); !errors.Is(err, errUsage) {
+		t.Fatalf("expected errUsage, got %v", err)
+	}
+	if err := run(ctx, append(global, "dump"), nil, io.Discard, io.Discard); !errors.Is(err, errUsage) {
+		t.Fatalf("expected -table required, got %v", err)
+	}
+
+	var stdout bytes.Buffer
+	if err := run(ctx, append(global, "dump", "-table", "T"), nil, &stdout, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(stdout.String()), "\n"); len(lines) != 2 || !strings.Contains(lines[1], `"n":{"N":"1"}`) {
+		t.Fatalf("unexpected dump: %q", stdout.String())
+	}
+	file := filepath.Join(t.TempDir(), "T.jsonl")
+	if err := run(ctx, append(global, "dump", "-table", "T", "-file", file), nil, io.Discard, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := os.ReadFile(file); err != nil || !bytes.Equal(b, stdout.Bytes()) {
+		t.Fatalf("unexpected dump file: %q, %v", b, err)
+	}
+
+	// a failed write fails the dump
+	if err := run(ctx, append(global, "dump", "-table", "T"), nil, failingWriter{}, io.Discard); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("expected the write error, got %v", err)
+	}
+
+	// a dump failing on its second page keeps the previous file, without a partial one beside it
+	broken := []string{"-endpoint", describeEndpoint(t), "-region", "us-east-1", "-local"}
+	if err := run(ctx, append(broken, "dump", "-table", "T", "-file", file), nil, io.Discard, io.Discard); err == nil {
+		t.Fatal("expected the scan error")
+	}
+	if b, err := os.ReadFile(file); err != nil || !bytes.Equal(b, stdout.Bytes()) {
+		t.Fatalf("previous dump file replaced: %q, %v", b, err)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(file)); len(entries) != 1 {
+		t.Fatalf("temporary dump left behind: %v", entries)
+	}
+
+	if err := run(ctx, append(broken, "query", "-table", "T", "-pk", "a", "-sk-op", "gt"), nil, io.Discard, io.Discard); !errors.Is(err, errUsage) {
+		t.Fatalf("expected -sk-op without -sk rejected, got %v", err)
+	}
+	if err := run(ctx, append(broken, "query", "-table", "T", "-pk", "a", "-index", "gsi-nope"), nil, io.Discard, io.Discard); !errors.Is(err, errUsage) || !strings.Contains(err.Error(), `unknown index "gsi-nope"`) {
+		t.Fatalf("expected the unknown index named, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/go-chujang/dynamox"
+)
+
+func (a *app) printJSON(v any) error {
+	enc := json.NewEncoder(a.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printRows prints rows in the selected format. columns fixes the leading
+// column order for table output; remaining keys follow alphabetically.
+func (a *app) printRows(rows []map[string]any, columns []string) error {
+	if a.format == "json" {
+		return a.printJSON(rows)
+	}
+	return a.printTable(rows, columns)
+}
+
+func (a *app) printTable(rows []map[string]any, columns []string) error {
+	seen := make(map[string]struct{}, len(columns))
+	for _, v := range columns {
+		seen[v] = struct{}{}
+	}
+	var rest []string
+	for _, row := range rows {
+		for k := range row {
+			if _, exist := seen[k]; !exist {
+				seen[k] = struct{}{}
+				rest = append(rest, k)
+			}
+		}
+	}
+	slices.Sort(rest)
+	columns = append(slices.Clone(columns), rest...)
+
+	tw := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	for i, v := range columns {
+		if i > 0 {
+			fmt.Fprint(tw, "\t")
+		}
+		fmt.Fprint(tw, v)
+	}
+	fmt.Fprintln(tw)
+	for _, row := range rows {
+		for i, col := range columns {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprint(tw, cell(row[col]))
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+func cell(v any) string {
+	switch vv := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return vv
+	case json.Number:
+		return vv.String()
+	default:
+		b, err := json.Marshal(vv)
+		if err != nil {
+			return fmt.Sprint(vv)
+		}
+		return string(b)
+	}
+}
+
+type page struct {
+	Count int32            `json:"count"`
+	Items []map[string]any `json:"items"`
+	Next  string           `json:"next,omitempty"`
+}
+
+// printPage prints query/scan results; the next token is a PaginationKey token
+// that can be passed back with -start.
+func (a *app) printPage(count int32, items []rawItem, next dynamox.PaginationKey, keyColumns []string) error {
+	out := page{Count: count, Items: make([]map[string]any, 0, len(items))}
+	for _, v := range items {
+		m, err := v.plain()
+		if err != nil {
+			return err
+		}
+		out.Items = append(out.Items, m)
+	}
+	var err error
+	if out.Next, err = next.Export(); err != nil {
+		return err
+	}
+
+	if a.format == "json" {
+		return a.printJSON(out)
+	}
+	if err := a.printTable(out.Items, keyColumns); err != nil {
+		return err
+	}
+	if out.Next != "" {
+		fmt.Fprintln(a.stderr, "next:", out.Next)
+	}
+	return nil
+}
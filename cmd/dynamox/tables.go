@@ -0,0 +1,317 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-chujang/dynamox"
+)
+
+func (a *app) tables(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: tables ls | describe | create | drop", errUsage)
+	}
+	switch args[0] {
+	case "ls", "list":
+		return a.tablesList(ctx)
+	case "describe":
+		return a.tablesDescribe(ctx, args[1:])
+	case "create":
+		return a.tablesCreate(ctx, args[1:])
+	case "drop":
+		return a.tablesDrop(ctx, args[1:])
+	default:
+		return fmt.Errorf("%w: unknown tables command %q", errUsage, args[0])
+	}
+}
+
+func (a *app) tablesList(ctx context.Context) error {
+	var names []string
+	paginator := dynamodb.NewListTablesPaginator(a.cli.SDK(), &dynamodb.ListTablesInput{})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		names = append(names, out.TableNames...)
+	}
+	rows := make([]map[string]any, 0, len(names))
+	for _, v := range names {
+		rows = append(rows, map[string]any{"table": v})
+	}
+	return a.printRows(rows, []string{"table"})
+}
+
+type tableSummary struct {
+	Table      string         `json:"table"`
+	Status     string         `json:"status"`
+	ItemCount  int64          `json:"itemCount"`
+	SizeBytes  int64          `json:"sizeBytes"`
+	Billing    string         `json:"billing,omitempty"`
+	PK         string         `json:"pk"`
+	SK         string         `json:"sk,omitempty"`
+	Attributes map[string]any `json:"attributes"`
+	Indexes    []indexSummary `json:"indexes,omitempty"`
+}
+
+type indexSummary struct {
+	Name       string `json:"name"`
+	Kind       string `json:"kind"`
+	PK         string `json:"pk"`
+	SK         string `json:"sk,omitempty"`
+	Projection string `json:"projection"`
+}
+
+func (a *app) tablesDescribe(ctx context.Context, args []string) error {
+	fs := a.flagSet("tables describe")
+	table := fs.String("table", "", "table name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireTable(*table); err != nil {
+		return err
+	}
+	desc, err := describeTable(ctx, a.cli, *table)
+	if err != nil {
+		return err
+	}
+
+	summary := tableSummary{
+		Table:      aws.ToString(desc.TableName),
+		Status:     string(desc.TableStatus),
+		ItemCount:  aws.ToInt64(desc.ItemCount),
+		SizeBytes:  aws.ToInt64(desc.TableSizeBytes),
+		Attributes: make(map[string]any, len(desc.AttributeDefinitions)),
+	}
+	if desc.BillingModeSummary != nil {
+		summary.Billing = string(desc.BillingModeSummary.BillingMode)
+	}
+	summary.PK, summary.SK = keySchemaFields(desc.KeySchema)
+	for _, v := range desc.AttributeDefinitions {
+		summary.Attributes[aws.ToString(v.AttributeName)] = string(v.AttributeType)
+	}
+	for _, v := range desc.GlobalSecondaryIndexes {
+		pk, sk := keySchemaFields(v.KeySchema)
+		summary.Indexes = append(summary.Indexes, indexSummary{
+			Name: aws.ToString(v.IndexName), Kind: dynamox.GSI.String(), PK: pk, SK: sk,
+			Projection: projectionString(v.Projection),
+		})
+	}
+	for _, v := range desc.LocalSecondaryIndexes {
+		pk, sk := keySchemaFields(v.KeySchema)
+		summary.Indexes = append(summary.Indexes, indexSummary{
+			Name: aws.ToString(v.IndexName), Kind: dynamox.LSI.String(), PK: pk, SK: sk,
+			Projection: projectionString(v.Projection),
+		})
+	}
+
+	if a.format == "table" {
+		rows := []map[string]any{{"name": summary.Table, "kind": "table", "pk": summary.PK, "sk": summary.SK, "projection": ""}}
+		for _, v := range summary.Indexes {
+			rows = append(rows, map[string]any{"name": v.Name, "kind": v.Kind, "pk": v.PK, "sk": v.SK, "projection": v.Projection})
+		}
+		fmt.Fprintf(a.stdout, "status: %s, items: %d, size: %d bytes\n", summary.Status, summary.ItemCount, summary.SizeBytes)
+		return a.printRows(rows, []string{"name", "kind", "pk", "sk", "projection"})
+	}
+	return a.printJSON(summary)
+}
+
+func (a *app) tablesCreate(ctx context.Context, args []string) error {
+	var (
+		fs    = a.flagSet("tables create")
+		table = fs.String("table", "", "table name")
+		pk    = fs.String("pk", "", "partition key, name:S|N|B")
+		sk    = fs.String("sk", "", "sort key, name:S|N|B")
+		gsis  multiFlag
+		lsis  multiFlag
+	)
+	fs.Var(&gsis, "gsi", "global secondary index, pk:S[,sk:S] (repeatable)")
+	fs.Var(&lsis, "lsi", "local secondary index sort key, sk:S (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireTable(*table); err != nil {
+		return err
+	}
+	pkDef, err := parseAttrDef(*pk)
+	if err != nil {
+		return fmt.Errorf("-pk: %w", err)
+	}
+
+	var (
+		attrDefs  = newAttrDefSet()
+		keySchema = []types.KeySchemaElement{{KeyType: types.KeyTypeHash, AttributeName: pkDef.AttributeName}}
+		input     = &dynamodb.CreateTableInput{
+			TableName:   aws.String(*table),
+			BillingMode: types.BillingModePayPerRequest,
+		}
+	)
+	attrDefs.add(pkDef)
+	if *sk != "" {
+		skDef, err := parseAttrDef(*sk)
+		if err != nil {
+			return fmt.Errorf("-sk: %w", err)
+		}
+		attrDefs.add(skDef)
+		keySchema = append(keySchema, types.KeySchemaElement{KeyType: types.KeyTypeRange, AttributeName: skDef.AttributeName})
+	}
+	input.KeySchema = keySchema
+
+	for _, v := range gsis {
+		defs, err := parseAttrDefs(v)
+		if err != nil {
+			return fmt.Errorf("-gsi: %w", err)
+		}
+		index, err := dynamox.NewGSI(defs[0], defs[1:]...)
+		if err != nil {
+			return fmt.Errorf("-gsi: %w", err)
+		}
+		for _, def := range index.KeyAttrDef() {
+			attrDefs.add(def)
+		}
+		gsi, err := index.GlobalSecondaryIndex()
+		if err != nil {
+			return fmt.Errorf("-gsi: %w", err)
+		}
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, gsi)
+	}
+	for _, v := range lsis {
+		skDef, err := parseAttrDef(v)
+		if err != nil {
+			return fmt.Errorf("-lsi: %w", err)
+		}
+		index, err := dynamox.NewLSI(pkDef, skDef)
+		if err != nil {
+			return fmt.Errorf("-lsi: %w", err)
+		}
+		attrDefs.add(skDef)
+		lsi, err := index.LocalSecondaryIndex()
+		if err != nil {
+			return fmt.Errorf("-lsi: %w", err)
+		}
+		input.LocalSecondaryIndexes = append(input.LocalSecondaryIndexes, lsi)
+	}
+	input.AttributeDefinitions = attrDefs.list
+
+	out, err := a.cli.SDK().CreateTable(ctx, input)
+	if err != nil {
+		return err
+	}
+	return a.printRows([]map[string]any{{
+		"table":  aws.ToString(out.TableDescription.TableName),
+		"status": string(out.TableDescription.TableStatus),
+	}}, []string{"table", "status"})
+}
+
+func (a *app) tablesDrop(ctx context.Context, args []string) error {
+	var (
+		fs    = a.flagSet("tables drop")
+		table = fs.String("table", "", "table name")
+		yes   = fs.Bool("yes", false, "confirm deletion")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireTable(*table); err != nil {
+		return err
+	}
+	if !*yes {
+		return fmt.Errorf("%w: refusing to drop %q without -yes", errUsage, *table)
+	}
+	out, err := a.cli.SDK().DeleteTable(ctx, &dynamodb.DeleteTableInput{TableName: aws.String(*table)})
+	if err != nil {
+		return err
+	}
+	return a.printRows([]map[string]any{{
+		"table":  aws.ToString(out.TableDescription.TableName),
+		"status": string(out.TableDescription.TableStatus),
+	}}, []string{"table", "status"})
+}
+
+/////////////////////////////////////////////////////////////////////////////
+
+func describeTable(ctx context.Context, cli *dynamox.Client, table string) (*types.TableDescription, error) {
+	out, err := cli.SDK().DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+	if err != nil {
+		return nil, err
+	}
+	return out.Table, nil
+}
+
+func keySchemaFields(schema []types.KeySchemaElement) (pk, sk string) {
+	for _, v := range schema {
+		switch v.KeyType {
+		case types.KeyTypeHash:
+			pk = aws.ToString(v.AttributeName)
+		case types.KeyTypeRange:
+			sk = aws.ToString(v.AttributeName)
+		}
+	}
+	return pk, sk
+}
+
+func projectionString(proj *types.Projection) string {
+	if proj == nil {
+		return ""
+	}
+	if proj.ProjectionType == types.ProjectionTypeInclude {
+		return fmt.Sprintf("%s(%s)", proj.ProjectionType, strings.Join(proj.NonKeyAttributes, ","))
+	}
+	return string(proj.ProjectionType)
+}
+
+// name:S, name:N or name:B (type defaults to S)
+func parseAttrDef(s string) (types.AttributeDefinition, error) {
+	name, typ, found := strings.Cut(s, ":")
+	if name == "" {
+		return types.AttributeDefinition{}, dynamox.ErrInvalidAttributeDefinition
+	}
+	scalar := types.ScalarAttributeTypeS
+	if found {
+		scalar = types.ScalarAttributeType(strings.ToUpper(typ))
+	}
+	switch scalar {
+	case types.ScalarAttributeTypeS, types.ScalarAttributeTypeN, types.ScalarAttributeTypeB:
+	default:
+		return types.AttributeDefinition{}, fmt.Errorf("%w: %s", dynamox.ErrInvalidAttributeDefinition, s)
+	}
+	return types.AttributeDefinition{AttributeName: aws.String(name), AttributeType: scalar}, nil
+}
+
+func parseAttrDefs(s string) ([]types.AttributeDefinition, error) {
+	split := strings.Split(s, ",")
+	if len(split) > 2 {
+		return nil, fmt.Errorf("%w: %s", dynamox.ErrInvalidAttributeDefinition, s)
+	}
+	defs := make([]types.AttributeDefinition, 0, len(split))
+	for _, v := range split {
+		def, err := parseAttrDef(v)
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
+
+type attrDefSet struct {
+	seen map[string]struct{}
+	list []types.AttributeDefinition
+}
+
+func newAttrDefSet() *attrDefSet {
+	return &attrDefSet{seen: make(map[string]struct{})}
+}
+
+func (s *attrDefSet) add(def types.AttributeDefinition) {
+	name := aws.ToString(def.AttributeName)
+	if _, exist := s.seen[name]; exist {
+		return
+	}
+	s.seen[name] = struct{}{}
+	s.list = append(s.list, def)
+}
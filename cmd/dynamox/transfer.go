@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-chujang/dynamox"
+)
+
+const maxUnprocessedRetry = 8
+
+func (a *app) dump(ctx context.Context, args []string) (err error) {
+	var (
+		fs    = a.flagSet("dump")
+		table = fs.String("table", "", "table name")
+		file  = fs.String("file", "-", "output file, - for stdout")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireTable(*table); err != nil {
+		return err
+	}
+
+	w := a.stdout
+	if *file != "-" {
+		// written beside the file and renamed over it once complete, a failed dump leaves no partial file
+		f, createErr := os.CreateTemp(filepath.Dir(*file), "."+filepath.Base(*file)+".*")
+		if createErr != nil {
+			return createErr
+		}
+		defer func() {
+			// a failed close may lose what was written
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err == nil {
+				err = os.Rename(f.Name(), *file)
+			}
+			if err != nil {
+				os.Remove(f.Name())
+			}
+		}()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+
+	var (
+		total    int
+		startKey dynamox.PaginationKey
+	)
+	for {
+		var items []rawItem
+		query := dynamox.NewCtxQuery(ctx).SetTable(*table).SetConsistentRead(true).SetStartKey(startKey)
+		_, next, err := a.cli.Scan(query, &items)
+		if err != nil {
+			return err
+		}
+		for _, v := range items {
+			line, err := encodeDynamoJSON(v)
+			if err != nil {
+				return err
+			}
+			if _, err = bw.Write(append(line, '\n')); err != nil {
+				return err
+			}
+		}
+		total += len(items)
+		if len(next) == 0 {
+			break
+		}
+		startKey = next
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stderr, "dumped %d items from %s\n", total, *table)
+	return nil
+}
+
+func (a *app) load(ctx context.Context, args []string) error {
+	var (
+		fs    = a.flagSet("load")
+		table = fs.String("table", "", "table name")
+		file  = fs.String("file", "-", "input file of DynamoDB JSON lines, - for stdin")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireTable(*table); err != nil {
+		return err
+	}
+
+	var r io.Reader = a.stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var (
+		total   int
+		lineNo  int
+		pending = make([]types.WriteRequest, 0, dynamox.BatchWriteLimit)
+		scanner = bufio.NewScanner(r)
+	)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20) // item size limit is 400KB
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		item, err := decodeDynamoJSON(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		pending = append(pending, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+		if len(pending) == dynamox.BatchWriteLimit {
+			if err = a.batchPut(ctx, *table, pending); err != nil {
+				return err
+			}
+			total += len(pending)
+			pending = pending[:0]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		if err := a.batchPut(ctx, *table, pending); err != nil {
+			return err
+		}
+		total += len(pending)
+	}
+	fmt.Fprintf(a.stderr, "loaded %d items into %s\n", total, *table)
+	return nil
+}
+
+// batchPut writes one batch and retries UnprocessedItems with backoff
+func (a *app) batchPut(ctx context.Context, table string, requests []types.WriteRequest) error {
+	items := map[string][]types.WriteRequest{table: requests}
+	backoff := 50 * time.Millisecond
+	for range maxUnprocessedRetry {
+		unprocessed, err := a.cli.BatchWrite(dynamox.NewCtxQuery(ctx).SetBatchWriteItems(items))
+		if len(unprocessed) == 0 {
+			return err
+		}
+		items = unprocessed
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return dynamox.ErrUnprocessedItems
+}
@@ -0,0 +1,294 @@
+package dynamox
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SegmentCodec converts a field value to and from one segment of a composite key
+type SegmentCodec interface {
+	EncodeSegment(v reflect.Value) (string, error)
+	DecodeSegment(s string, v reflect.Value) error // v is settable
+}
+
+// KeySegment is a named segment of CompositeKeySchema, bound to the struct field
+// with the same dynamodbav name or Go field name
+type KeySegment struct {
+	Name  string
+	Codec SegmentCodec // nil: plain text of the value
+}
+
+// Seg declares a segment, e.g. Seg("orderedAt", SortableTimeSegment)
+func Seg(name string, codec ...SegmentCodec) KeySegment {
+	s := KeySegment{Name: name}
+	if len(codec) > 0 {
+		s.Codec = codec[0]
+	}
+	return s
+}
+
+func (s KeySegment) codec() SegmentCodec {
+	if s.Codec == nil {
+		return textSegment{}
+	}
+	return s.Codec
+}
+
+// CompositeKeySchema declares the ordered segments of a composite key after a SortKeyPrefix
+//
+//	orderSK := NewCompositeKeySchema("ORDER", Seg("status"), Seg("orderId"))
+//	sk, _ := orderSK.BuildFrom(order)                // ORDER#SHIPPED#01J...
+//	prefix, _ := orderSK.Prefix("SHIPPED")            // ORDER#SHIPPED#
+//	NewKeyCondBuilder().WithSK(BeginsWith, "sk", prefix)
+//	orderSK.Parse(sk, &order)
+type CompositeKeySchema struct {
+	prefix   SortKeyPrefix
+	segments []KeySegment
+	cv       Conventions // separator; "" is the global one
+	escaped  bool
+}
+
+func NewCompositeKeySchema(prefix SortKeyPrefix, segments ...KeySegment) *CompositeKeySchema {
+	return &CompositeKeySchema{prefix: prefix, segments: segments}
+}
+
+// WithConventions returns a copy of s composing with the separator of cv, e.g. Client.Conventions()
+func (s *CompositeKeySchema) WithConventions(cv Conventions) *CompositeKeySchema {
+	return &CompositeKeySchema{prefix: s.prefix, segments: s.segments, cv: cv, escaped: s.escaped}
+}
+
+// Escaped returns a copy of s escaping separators inside segments (see escapeSegment), so that
+// values such as URLs split back unambiguously. escaped keys differ from plain ones only in the
+// segments holding the separator or the rune after it ('$' for '#'), opt in on new key layouts.
+func (s *CompositeKeySchema) Escaped() *CompositeKeySchema {
+	return &CompositeKeySchema{prefix: s.prefix, segments: s.segments, cv: s.cv, escaped: true}
+}
+
+func (s *CompositeKeySchema) SortKeyPrefix() SortKeyPrefix { return s.prefix }
+func (s *CompositeKeySchema) Segments() []KeySegment       { return s.segments }
+
+// Build composites values in segment order
+func (s *CompositeKeySchema) Build(values ...any) (string, error) {
+	if len(values) != len(s.segments) {
+		return "", fmt.Errorf("%w: %d of %d segments", ErrInsufficientCompositeKeySrc, len(values), len(s.segments))
+	}
+	return s.build(values)
+}
+
+// BuildFrom composites the fields of struct v named by the segments
+func (s *CompositeKeySchema) BuildFrom(v any) (string, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return "", fmt.Errorf("%w: %T is not struct", ErrInsufficientCompositeKeySrc, v)
+	}
+	values := make([]any, 0, len(s.segments))
+	for _, seg := range s.segments {
+		field, err := segmentField(rv, seg.Name)
+		if err != nil {
+			return "", err
+		}
+		if field.IsZero() {
+			return "", fmt.Errorf("%w: %s", ErrInsufficientCompositeKeySrc, seg.Name)
+		}
+		values = append(values, field.Interface())
+	}
+	return s.build(values)
+}
+
+// Prefix composites the leading subset of segments for begins_with,
+// ending with the separator so that "A" does not match "AB"
+func (s *CompositeKeySchema) Prefix(values ...any) (string, error) {
+	if len(values) > len(s.segments) {
+		return "", fmt.Errorf("%w: %d of %d segments", ErrInsufficientCompositeKeySrc, len(values), len(s.segments))
+	}
+	if len(values) == len(s.segments) {
+		return s.build(values)
+	}
+	if len(values) == 0 && s.prefix == "" {
+		return "", nil
+	}
+	key, err := s.build(values)
+	if err != nil {
+		return "", err
+	}
+	return key + s.cv.sep(), nil
+}
+
+// UpperBound is the inclusive upper end of Between for the keys beginning with values:
+// the composite of values followed by the rune after the separator, which sorts past
+// every key continuing with the separator
+//
+//	lower, _ := scoreSK.Prefix(int64(-100))
+//	upper, _ := scoreSK.UpperBound(int64(100)) // score 100 included
+func (s *CompositeKeySchema) UpperBound(values ...any) (string, error) {
+	if len(values) > len(s.segments) {
+		return "", fmt.Errorf("%w: %d of %d segments", ErrInsufficientCompositeKeySrc, len(values), len(s.segments))
+	}
+	key, err := s.build(values)
+	if err != nil {
+		return "", err
+	}
+	r, _ := utf8.DecodeRuneInString(s.cv.sep())
+	return key + string(r+1), nil
+}
+
+func (s *CompositeKeySchema) build(values []any) (string, error) {
+	srcs := make([]string, 0, len(values))
+	for i, v := range values {
+		seg, err := s.segments[i].codec().EncodeSegment(reflect.ValueOf(v))
+		if err != nil {
+			return "", fmt.Errorf("segment %s: %w", s.segments[i].Name, err)
+		}
+		srcs = append(srcs, seg)
+	}
+	if s.prefix != "" {
+		srcs = append([]string{s.prefix.String()}, srcs...)
+	}
+	if s.escaped {
+		return escapedCompositeKey(s.cv.sep(), srcs...), nil
+	}
+	return compositeKeyWith(s.cv.sep(), srcs...), nil
+}
+
+// Split returns the raw segments of key, after the prefix
+func (s *CompositeKeySchema) Split(key string) ([]string, error) {
+	var parts []string
+	if s.escaped {
+		parts = splitEscapedCompositeKey(s.cv.sep(), key)
+	} else {
+		parts = s.cv.SplitCompositeKey(key)
+	}
+	if s.prefix != "" {
+		if len(parts) == 0 || parts[0] != s.prefix.String() {
+			return nil, fmt.Errorf("%w: %q has no prefix %q", ErrinsufficientParseCompositeKey, key, s.prefix)
+		}
+		parts = parts[1:]
+	}
+	if len(parts) != len(s.segments) {
+		return nil, fmt.Errorf("%w: %q has %d of %d segments", ErrinsufficientParseCompositeKey, key, len(parts), len(s.segments))
+	}
+	return parts, nil
+}
+
+// Parse sets the fields of the struct pointed by out from key
+func (s *CompositeKeySchema) Parse(key string, out any) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return ErrOutputNilPointer
+	}
+	parts, err := s.Split(key)
+	if err != nil {
+		return err
+	}
+	for i, seg := range s.segments {
+		field, err := segmentField(rv.Elem(), seg.Name)
+		if err != nil {
+			return err
+		}
+		if err = seg.codec().DecodeSegment(parts[i], field); err != nil {
+			return fmt.Errorf("%w: segment %s: %w", ErrinsufficientParseCompositeKey, seg.Name, err)
+		}
+	}
+	return nil
+}
+
+// SplitCompositeKey is the reverse of SortKeyPrefix.Composite
+func SplitCompositeKey(key string) []string {
+	return splitCompositeKeyWith(CompositeKeySep(), key)
+}
+
+func splitCompositeKeyWith(sep, key string) []string {
+	if key == "" {
+		return nil
+	}
+	return strings.Split(key, sep)
+}
+
+func splitEscapedCompositeKey(sep, key string) []string {
+	parts := splitCompositeKeyWith(sep, key)
+	for i, v := range parts {
+		parts[i] = unescapeSegment(v, sep)
+	}
+	return parts
+}
+
+func segmentField(rv reflect.Value, name string) (reflect.Value, error) {
+	for _, field := range reflect.VisibleFields(rv.Type()) {
+		if field.IsExported() && !field.Anonymous && (field.Name == name || attrName(field) == name) {
+			return rv.FieldByIndex(field.Index), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("%w: %s has no field %q", ErrInsufficientMember, rv.Type(), name)
+}
+
+/////////////////////////////////////////////////////////////////////////////
+
+// textSegment is the plain text of strings, numbers, bools and encoding.TextMarshaler
+type textSegment struct{}
+
+func (textSegment) EncodeSegment(v reflect.Value) (string, error) {
+	if !v.IsValid() {
+		return "", ErrInsufficientCompositeKeySrc
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		return string(b), err
+	}
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String(), nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	}
+	return "", fmt.Errorf("unsupported segment type %s", v.Type())
+}
+
+func (textSegment) DecodeSegment(s string, v reflect.Value) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported segment type %s", v.Type())
+	}
+	return nil
+}
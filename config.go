@@ -17,8 +17,7 @@ const (
 )
 
 var (
-	newTimestamp    atomic.Value
-	parseTimestamp  atomic.Value
+	timestampUnitV  atomic.Value
 	compositeKeySep atomic.Value
 )
 
@@ -27,20 +26,13 @@ func init() {
 	SetCompositeKeySep(defaultCompositeKeySeperator)
 }
 
+// SetTimestampUnit sets the unit of Time, TimeNow and TimeByTime
 func SetTimestampUnit(tsu timestampUnit) {
 	switch tsu {
-	case Seconds:
-		newTimestamp.Store(func() int64 { return time.Now().Unix() })
-		parseTimestamp.Store(func(sec int64) time.Time { return time.Unix(sec, 0) })
-	case MicroSeconds:
-		newTimestamp.Store(func() int64 { return time.Now().UnixMicro() })
-		parseTimestamp.Store(func(usec int64) time.Time { return time.Unix(usec/1e6, (usec%1e6)*1e3) })
-	case NanoSeconds:
-		newTimestamp.Store(func() int64 { return time.Now().UnixNano() })
-		parseTimestamp.Store(func(nsec int64) time.Time { return time.Unix(0, nsec) })
-	default: // MilliSeconds
-		newTimestamp.Store(func() int64 { return time.Now().UnixMilli() })
-		parseTimestamp.Store(func(msec int64) time.Time { return time.Unix(msec/1e3, (msec%1e3)*1e6) })
+	case Seconds, MicroSeconds, NanoSeconds:
+		timestampUnitV.Store(tsu)
+	default:
+		timestampUnitV.Store(MilliSeconds)
 	}
 }
 
@@ -50,16 +42,78 @@ func SetCompositeKeySep(sep string) {
 	}
 }
 
+func TimestampUnit() timestampUnit {
+	return timestampUnitV.Load().(timestampUnit)
+}
+
 func newTimestampFn() int64 {
-	fn := newTimestamp.Load().(func() int64)
-	return fn()
+	return TimestampUnit().fromTime(GlobalClock().Now())
 }
 
 func parseTimestampFn(ts int64) time.Time {
-	fn := parseTimestamp.Load().(func(int64) time.Time)
-	return fn(ts)
+	return TimestampUnit().toTime(ts)
 }
 
 func CompositeKeySep() string {
 	return compositeKeySep.Load().(string)
 }
+
+func (tsu timestampUnit) fromTime(t time.Time) int64 {
+	switch tsu {
+	case Seconds:
+		return t.Unix()
+	case MicroSeconds:
+		return t.UnixMicro()
+	case NanoSeconds:
+		return t.UnixNano()
+	default:
+		return t.UnixMilli()
+	}
+}
+
+func (tsu timestampUnit) toTime(ts int64) time.Time {
+	switch tsu {
+	case Seconds:
+		return time.Unix(ts, 0)
+	case MicroSeconds:
+		return time.UnixMicro(ts)
+	case NanoSeconds:
+		return time.Unix(0, ts)
+	default:
+		return time.UnixMilli(ts)
+	}
+}
+
+// Conventions are the timestamp unit and composite key separator a Client writes times and keys with;
+// the package globals (SetTimestampUnit, SetCompositeKeySep) are the defaults, see GlobalConventions
+type Conventions struct {
+	TimestampUnit   timestampUnit
+	CompositeKeySep string // "": the global one
+}
+
+func GlobalConventions() Conventions {
+	return Conventions{TimestampUnit: TimestampUnit(), CompositeKeySep: CompositeKeySep()}
+}
+
+func (cv Conventions) sep() string {
+	if cv.CompositeKeySep == "" {
+		return CompositeKeySep()
+	}
+	return cv.CompositeKeySep
+}
+
+// Composite is prefix.Composite(srcs...) with the separator of cv
+func (cv Conventions) Composite(prefix SortKeyPrefix, srcs ...string) string {
+	return compositeKeyWith(cv.sep(), append([]string{prefix.String()}, srcs...)...)
+}
+
+// SplitCompositeKey is SplitCompositeKey with the separator of cv
+func (cv Conventions) SplitCompositeKey(key string) []string {
+	return splitCompositeKeyWith(cv.sep(), key)
+}
+
+// TimeOf converts t into a Time of cv's unit
+func (cv Conventions) TimeOf(t time.Time) Time { return Time(cv.TimestampUnit.fromTime(t)) }
+
+// ToTime is the reverse of TimeOf
+func (cv Conventions) ToTime(ts Time) time.Time { return cv.TimestampUnit.toTime(int64(ts)) }
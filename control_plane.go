@@ -52,3 +52,14 @@ func (c *Client) TableApproximateItemCount(ctx context.Context, name string) (in
 	}
 	return *out.Table.ItemCount, nil
 }
+
+// TableIndexes describes the secondary indexes of the table, see IndexesOf
+func (c *Client) TableIndexes(ctx context.Context, name string) ([]Index, error) {
+	out, err := c.SDK().DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(name),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return IndexesOf(out.Table)
+}
@@ -41,6 +41,12 @@ type CtxQuery struct {
 	returnValuesOnConditionCheckFailure types.ReturnValuesOnConditionCheckFailure // [put, update] none is default
 
 	keyCondBuilder *KeyCondBuilder // [query]
+	registry       *EntityRegistry // [get, query, scan] decode into KeyedItem by registered type
+	projection     []string        // [get, query, scan, batchGet] derived from the output type
+	indexOf        *Index          // [query, scan] by SetIndexOf or KeyCondBuilder.WithIndex
+	hydrate        bool            // [query] BatchGet the full items of a KEYS_ONLY or INCLUDE index
+	startToken     string          // [query, scan] opened into startKey by tokenSealer
+	tokenSealer    *TokenSealer
 }
 
 func NewCtxQuery(c ...context.Context) *CtxQuery {
@@ -89,6 +95,13 @@ func (cq *CtxQuery) SetStartKey(m PaginationKey) *CtxQuery {
 	return cq
 }
 
+// SetStartToken continues from a token of TokenSealer.SealNext, opened against this query when parsed;
+// a forged token or one sealed for another query fails before sending
+func (cq *CtxQuery) SetStartToken(token string, sealer *TokenSealer) *CtxQuery {
+	cq.startToken, cq.tokenSealer = token, sealer
+	return cq
+}
+
 func (cq *CtxQuery) SetIndex(idx string) *CtxQuery {
 	if idx != "" {
 		cq.index = &idx
@@ -96,6 +109,13 @@ func (cq *CtxQuery) SetIndex(idx string) *CtxQuery {
 	return cq
 }
 
+// SetIndexOf reads through idx, e.g. scanning a sparse index for the items in it only;
+// ConsistentRead on a GSI fails before sending
+func (cq *CtxQuery) SetIndexOf(idx Index) *CtxQuery {
+	cq.indexOf = &idx
+	return cq.SetIndex(idx.Name())
+}
+
 func (cq *CtxQuery) SetLimit(l int32) *CtxQuery {
 	if l > 0 {
 		cq.limit = &l
@@ -190,6 +210,30 @@ func (cq *CtxQuery) SetKeyCondBuilder(kcb *KeyCondBuilder) *CtxQuery {
 	return cq
 }
 
+// SetProjectionOf projects the attributes of the output type v, key attributes always included.
+// an explicit projection (SetProjectExpr, KeyCondBuilder.WithProj) takes precedence
+func (cq *CtxQuery) SetProjectionOf(v any) *CtxQuery {
+	attrs, err := ProjectionOf(v)
+	if err != nil {
+		return cq.setInsufficientCause(err)
+	}
+	cq.projection = attrs
+	return cq
+}
+
+// SetHydrate fetches the full items of a query on a KEYS_ONLY or INCLUDE index (SetIndexOf, KeyCondBuilder.WithIndex)
+// by chunked BatchGet, in index order; the table keys are derived from the output type or the EntityRegistry
+func (cq *CtxQuery) SetHydrate(h bool) *CtxQuery {
+	cq.hydrate = h
+	return cq
+}
+
+// output must be *KeyedItem for get, *[]KeyedItem for query and scan
+func (cq *CtxQuery) SetEntityRegistry(r *EntityRegistry) *CtxQuery {
+	cq.registry = r
+	return cq
+}
+
 func (cq *CtxQuery) AppendBatchWriteItems(table string, items []types.WriteRequest) *CtxQuery {
 	if cq.batchWriteItems == nil {
 		cq.batchWriteItems = make(map[string][]types.WriteRequest, 1)
@@ -1,14 +1,18 @@
 package dynamox
 
 import (
+	"fmt"
+
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
 func (cq CtxQuery) get() (*dynamodb.GetItemInput, error) {
 	if !cq.required(cq.key).isValidWithTable() {
 		return nil, cq.errWithInsufficient()
 	}
+	cq.applyProjection(keyNamesOf(cq.key)...)
 	return &dynamodb.GetItemInput{
 		Key:                      cq.key,
 		TableName:                aws.String(cq.tableName),
@@ -28,6 +32,22 @@ func (cq CtxQuery) query() (*dynamodb.QueryInput, error) {
 			return nil, err
 		}
 		cq.ExprQuery(expr)
+		if idx, ok := cq.keyCondBuilder.Index(); ok {
+			cq.SetIndexOf(idx)
+		}
+	}
+	switch {
+	case cq.hydrating(): // projected on the BatchGet of the full items
+	case cq.keyCondBuilder != nil:
+		cq.applyProjection(cq.keyCondBuilder.partitionKeyField, cq.keyCondBuilder.sortKeyField)
+	default:
+		cq.applyProjection()
+	}
+	if err := cq.validateIndex(); err != nil {
+		return nil, err
+	}
+	if err := cq.openStartToken(); err != nil {
+		return nil, err
 	}
 	return &dynamodb.QueryInput{
 		TableName:                 aws.String(cq.tableName),
@@ -49,6 +69,13 @@ func (cq CtxQuery) scan() (*dynamodb.ScanInput, error) {
 	if !cq.required(cq.selectAttr).isValidWithTable() {
 		return nil, cq.errWithInsufficient()
 	}
+	if err := cq.validateIndex(); err != nil {
+		return nil, err
+	}
+	if err := cq.openStartToken(); err != nil {
+		return nil, err
+	}
+	cq.applyProjection()
 	return &dynamodb.ScanInput{
 		TableName:                 aws.String(cq.tableName),
 		ConsistentRead:            aws.Bool(cq.consistentRead),
@@ -120,7 +147,18 @@ func (cq CtxQuery) batchGet() (*dynamodb.BatchGetItemInput, error) {
 	if !cq.required(cq.batchGetItems).isValid() {
 		return nil, cq.errWithInsufficient()
 	}
-	return &dynamodb.BatchGetItemInput{RequestItems: cq.batchGetItems}, nil
+	if cq.projection == nil {
+		return &dynamodb.BatchGetItemInput{RequestItems: cq.batchGetItems}, nil
+	}
+	items := make(map[string]types.KeysAndAttributes, len(cq.batchGetItems))
+	for table, attrs := range cq.batchGetItems {
+		if attrs.ProjectionExpression == nil && len(attrs.Keys) > 0 {
+			attrs.ProjectionExpression, attrs.ExpressionAttributeNames = projectionExpr(
+				cq.projection, attrs.ExpressionAttributeNames, keyNamesOf(attrs.Keys[0])...)
+		}
+		items[table] = attrs
+	}
+	return &dynamodb.BatchGetItemInput{RequestItems: items}, nil
 }
 
 func (cq CtxQuery) transactionWrite() (*dynamodb.TransactWriteItemsInput, error) {
@@ -139,3 +177,21 @@ func (cq CtxQuery) transactionGet() (*dynamodb.TransactGetItemsInput, error) {
 	}
 	return &dynamodb.TransactGetItemsInput{TransactItems: cq.transactionGetItems}, nil
 }
+
+// applyProjection sets the projection derived by SetProjectionOf, unless one is explicit
+func (cq *CtxQuery) applyProjection(keyNames ...string) {
+	if cq.projection == nil || cq.projectExpr != nil {
+		return
+	}
+	cq.projectExpr, cq.exprAttrNames = projectionExpr(cq.projection, cq.exprAttrNames, keyNames...)
+	if cq.selectAttr == types.SelectAllAttributes {
+		cq.selectAttr = types.SelectSpecificAttributes
+	}
+}
+
+func (cq CtxQuery) validateIndex() error {
+	if cq.indexOf != nil && cq.consistentRead && cq.indexOf.Kind() == GSI {
+		return fmt.Errorf("%w: %s", ErrConsistentReadOnGSI, cq.indexOf.Name())
+	}
+	return nil
+}
@@ -87,7 +87,7 @@ func (cq *CtxQuery) required(member any) *CtxQuery {
 		}
 	case types.Select:
 		if v == types.SelectSpecificAttributes {
-			return cq.setInsufficient(cq.projectExpr == nil)
+			return cq.setInsufficient(cq.projectExpr == nil && cq.projection == nil)
 		}
 	case []types.TransactWriteItem:
 		if v == nil || len(v) > TransactionWriteLimit {
@@ -102,3 +102,10 @@ func (cq *CtxQuery) required(member any) *CtxQuery {
 	}
 	return cq
 }
+
+func (cq *CtxQuery) unmarshalList(l []map[string]types.AttributeValue, out any) error {
+	if cq.registry != nil {
+		return cq.registry.unmarshalList(l, out)
+	}
+	return UnmarshalListOfMapsByAny(l, out)
+}
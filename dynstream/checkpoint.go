@@ -0,0 +1,134 @@
+package dynstream
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-chujang/dynamox"
+)
+
+type Checkpoint struct {
+	SequenceNumber string // last processed record, empty if none
+	Finished       bool   // closed shard fully processed
+}
+
+type Checkpointer interface {
+	// zero Checkpoint without error if nothing saved yet
+	Load(ctx context.Context, streamArn, shardID string) (Checkpoint, error)
+	Save(ctx context.Context, streamArn, shardID string, cp Checkpoint) error
+}
+
+var (
+	_ Checkpointer      = (*MemoryCheckpointer)(nil)
+	_ Checkpointer      = (*TableCheckpointer)(nil)
+	_ dynamox.KeyedItem = (*checkpointItem)(nil)
+)
+
+/////////////////////////////////////////////////////////////////////////////
+// memory
+
+type MemoryCheckpointer struct {
+	mu sync.Mutex
+	m  map[[2]string]Checkpoint
+}
+
+func NewMemoryCheckpointer() *MemoryCheckpointer {
+	return &MemoryCheckpointer{m: make(map[[2]string]Checkpoint)}
+}
+
+func (mc *MemoryCheckpointer) Load(_ context.Context, streamArn, shardID string) (Checkpoint, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	return mc.m[[2]string{streamArn, shardID}], nil
+}
+
+func (mc *MemoryCheckpointer) Save(_ context.Context, streamArn, shardID string, cp Checkpoint) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.m[[2]string{streamArn, shardID}] = cp
+	return nil
+}
+
+/////////////////////////////////////////////////////////////////////////////
+// dynamodb table, pk: streamArn(S), sk: shardId(S)
+
+type checkpointKey struct {
+	table     string
+	StreamArn string `dynamodbav:"streamArn"`
+	ShardId   string `dynamodbav:"shardId"`
+}
+
+func (c checkpointKey) Table() string { return c.table }
+func (checkpointKey) PKField() string { return "streamArn" }
+func (checkpointKey) SKField() string { return "shardId" }
+func (c checkpointKey) PK() any       { return c.StreamArn }
+func (c checkpointKey) SK() any       { return c.ShardId }
+
+type checkpointItem struct {
+	checkpointKey
+	SequenceNumber string       `dynamodbav:"sequenceNumber,omitempty"`
+	Finished       bool         `dynamodbav:"finished"`
+	UpdatedAt      dynamox.Time `dynamodbav:"updatedAt"`
+}
+
+func (c *checkpointItem) GetKeyBase() dynamox.KeyBase { return &c.checkpointKey }
+func (c *checkpointItem) SaveSK() error               { return nil }
+
+type TableCheckpointer struct {
+	cli   *dynamox.Client
+	table string
+}
+
+func NewTableCheckpointer(cli *dynamox.Client, table string) *TableCheckpointer {
+	return &TableCheckpointer{cli: cli, table: table}
+}
+
+// EnsureTable creates the checkpoint table (on-demand billing) if it does not exist,
+// under the name resolved by the client's Options.TableName
+func (tc *TableCheckpointer) EnsureTable(ctx context.Context) error {
+	table := tc.cli.TableName(tc.table)
+	exist, err := tc.cli.TableExists(ctx, table)
+	if err != nil || exist {
+		return err
+	}
+	keybase := checkpointKey{}
+	_, err = tc.cli.SDK().CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName:   aws.String(table),
+		BillingMode: types.BillingModePayPerRequest,
+		KeySchema: []types.KeySchemaElement{
+			{KeyType: types.KeyTypeHash, AttributeName: aws.String(keybase.PKField())},
+			{KeyType: types.KeyTypeRange, AttributeName: aws.String(keybase.SKField())},
+		},
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String(keybase.PKField()), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String(keybase.SKField()), AttributeType: types.ScalarAttributeTypeS},
+		},
+	})
+	return err
+}
+
+func (tc *TableCheckpointer) Load(ctx context.Context, streamArn, shardID string) (Checkpoint, error) {
+	item := checkpointItem{checkpointKey: checkpointKey{table: tc.table, StreamArn: streamArn, ShardId: shardID}}
+	err := tc.cli.Cruder().Read(ctx, &item, true)
+	switch {
+	case errors.Is(err, dynamox.ErrNotFoundItem):
+		return Checkpoint{}, nil
+	case err != nil:
+		return Checkpoint{}, err
+	}
+	return Checkpoint{SequenceNumber: item.SequenceNumber, Finished: item.Finished}, nil
+}
+
+func (tc *TableCheckpointer) Save(ctx context.Context, streamArn, shardID string, cp Checkpoint) error {
+	item := checkpointItem{
+		checkpointKey:  checkpointKey{table: tc.table, StreamArn: streamArn, ShardId: shardID},
+		SequenceNumber: cp.SequenceNumber,
+		Finished:       cp.Finished,
+		UpdatedAt:      tc.cli.Now(),
+	}
+	return tc.cli.Cruder().Create(ctx, &item, false)
+}
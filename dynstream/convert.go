@@ -0,0 +1,92 @@
+package dynstream
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	streamtypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+)
+
+// dynamodbstreams declares its own AttributeValue, which dynamox/attributevalue cannot decode
+
+func fromStreamImage(m map[string]streamtypes.AttributeValue) map[string]types.AttributeValue {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]types.AttributeValue, len(m))
+	for k, v := range m {
+		out[k] = fromStreamAttr(v)
+	}
+	return out
+}
+
+func fromStreamAttr(av streamtypes.AttributeValue) types.AttributeValue {
+	switch v := av.(type) {
+	case *streamtypes.AttributeValueMemberS:
+		return &types.AttributeValueMemberS{Value: v.Value}
+	case *streamtypes.AttributeValueMemberN:
+		return &types.AttributeValueMemberN{Value: v.Value}
+	case *streamtypes.AttributeValueMemberB:
+		return &types.AttributeValueMemberB{Value: v.Value}
+	case *streamtypes.AttributeValueMemberBOOL:
+		return &types.AttributeValueMemberBOOL{Value: v.Value}
+	case *streamtypes.AttributeValueMemberNULL:
+		return &types.AttributeValueMemberNULL{Value: v.Value}
+	case *streamtypes.AttributeValueMemberSS:
+		return &types.AttributeValueMemberSS{Value: v.Value}
+	case *streamtypes.AttributeValueMemberNS:
+		return &types.AttributeValueMemberNS{Value: v.Value}
+	case *streamtypes.AttributeValueMemberBS:
+		return &types.AttributeValueMemberBS{Value: v.Value}
+	case *streamtypes.AttributeValueMemberL:
+		l := make([]types.AttributeValue, len(v.Value))
+		for i := range v.Value {
+			l[i] = fromStreamAttr(v.Value[i])
+		}
+		return &types.AttributeValueMemberL{Value: l}
+	case *streamtypes.AttributeValueMemberM:
+		return &types.AttributeValueMemberM{Value: fromStreamImage(v.Value)}
+	default:
+		return nil
+	}
+}
+
+func toStreamImage(m map[string]types.AttributeValue) map[string]streamtypes.AttributeValue {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]streamtypes.AttributeValue, len(m))
+	for k, v := range m {
+		out[k] = toStreamAttr(v)
+	}
+	return out
+}
+
+func toStreamAttr(av types.AttributeValue) streamtypes.AttributeValue {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return &streamtypes.AttributeValueMemberS{Value: v.Value}
+	case *types.AttributeValueMemberN:
+		return &streamtypes.AttributeValueMemberN{Value: v.Value}
+	case *types.AttributeValueMemberB:
+		return &streamtypes.AttributeValueMemberB{Value: v.Value}
+	case *types.AttributeValueMemberBOOL:
+		return &streamtypes.AttributeValueMemberBOOL{Value: v.Value}
+	case *types.AttributeValueMemberNULL:
+		return &streamtypes.AttributeValueMemberNULL{Value: v.Value}
+	case *types.AttributeValueMemberSS:
+		return &streamtypes.AttributeValueMemberSS{Value: v.Value}
+	case *types.AttributeValueMemberNS:
+		return &streamtypes.AttributeValueMemberNS{Value: v.Value}
+	case *types.AttributeValueMemberBS:
+		return &streamtypes.AttributeValueMemberBS{Value: v.Value}
+	case *types.AttributeValueMemberL:
+		l := make([]streamtypes.AttributeValue, len(v.Value))
+		for i := range v.Value {
+			l[i] = toStreamAttr(v.Value[i])
+		}
+		return &streamtypes.AttributeValueMemberL{Value: l}
+	case *types.AttributeValueMemberM:
+		return &streamtypes.AttributeValueMemberM{Value: toStreamImage(v.Value)}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,12 @@
+package dynstream
+
+import "errors"
+
+var (
+	ErrRequiredStreamArn    = errors.New("required streamArn")
+	ErrRequiredCheckpointer = errors.New("required checkpointer")
+	ErrUnknownShard         = errors.New("unknown shard")
+	ErrInvalidShardIterator = errors.New("invalid shard iterator")
+	ErrShardClosed          = errors.New("shard is closed")
+	ErrCheckpointTrimmed    = errors.New("checkpoint trimmed from the stream")
+)
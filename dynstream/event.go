@@ -0,0 +1,139 @@
+package dynstream
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	streamtypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+	"github.com/go-chujang/dynamox"
+)
+
+type EventType string
+
+const (
+	Insert EventType = EventType(streamtypes.OperationTypeInsert)
+	Modify EventType = EventType(streamtypes.OperationTypeModify)
+	Remove EventType = EventType(streamtypes.OperationTypeRemove)
+)
+
+// Record is a stream record with images already converted to dynamodb AttributeValues
+type Record struct {
+	EventID                 string
+	Type                    EventType
+	StreamArn               string
+	ShardID                 string
+	SequenceNumber          string
+	ApproximateCreationTime time.Time
+	Keys                    map[string]types.AttributeValue
+	NewImage                map[string]types.AttributeValue // empty on Remove or KEYS_ONLY/OLD_IMAGE view
+	OldImage                map[string]types.AttributeValue // empty on Insert or KEYS_ONLY/NEW_IMAGE view
+}
+
+func newRecord(streamArn, shardID string, rec streamtypes.Record) Record {
+	out := Record{
+		EventID:   aws.ToString(rec.EventID),
+		Type:      EventType(rec.EventName),
+		StreamArn: streamArn,
+		ShardID:   shardID,
+	}
+	if sr := rec.Dynamodb; sr != nil {
+		out.SequenceNumber = aws.ToString(sr.SequenceNumber)
+		out.ApproximateCreationTime = aws.ToTime(sr.ApproximateCreationDateTime)
+		out.Keys = fromStreamImage(sr.Keys)
+		out.NewImage = fromStreamImage(sr.NewImage)
+		out.OldImage = fromStreamImage(sr.OldImage)
+	}
+	return out
+}
+
+// Change is a typed event; New and Old are nil when the image is absent
+type Change[T dynamox.KeyedItem] struct {
+	Record
+	New T
+	Old T
+}
+
+/////////////////////////////////////////////////////////////////////////////
+
+// Matcher decides whether a record belongs to a registered item type
+type Matcher func(rec Record) bool
+
+func MatchAll(Record) bool { return true }
+
+// MatchSKPrefix matches records whose sort key begins with prefix
+func MatchSKPrefix(skField string, prefix dynamox.SortKeyPrefix) Matcher {
+	return func(rec Record) bool {
+		sk, ok := rec.Keys[skField].(*types.AttributeValueMemberS)
+		return ok && strings.HasPrefix(sk.Value, prefix.String())
+	}
+}
+
+// MatchAttr matches records whose discriminator attribute equals value in either image
+func MatchAttr(name, value string) Matcher {
+	return func(rec Record) bool {
+		for _, image := range []map[string]types.AttributeValue{rec.NewImage, rec.OldImage} {
+			if s, ok := image[name].(*types.AttributeValueMemberS); ok {
+				return s.Value == value
+			}
+		}
+		return false
+	}
+}
+
+type handler struct {
+	match    Matcher
+	dispatch func(ctx context.Context, rec Record) error
+}
+
+// Handle registers fn for records accepted by match (nil matches all).
+// NewImage/OldImage are decoded into T with dynamox.UnmarshalMap, so PostUnmarshal runs.
+//
+//	dynstream.Handle[profile](p, dynstream.MatchSKPrefix("sk", "CUST"), func(ctx context.Context, c dynstream.Change[*profile]) error { ... })
+func Handle[T any, PT interface {
+	*T
+	dynamox.KeyedItem
+}](p *Processor, match Matcher, fn func(ctx context.Context, change Change[PT]) error) {
+	if match == nil {
+		match = MatchAll
+	}
+	p.handlers = append(p.handlers, handler{
+		match: match,
+		dispatch: func(ctx context.Context, rec Record) error {
+			change := Change[PT]{Record: rec}
+			if len(rec.NewImage) > 0 {
+				change.New = PT(new(T))
+				if err := dynamox.UnmarshalMap(rec.NewImage, change.New); err != nil {
+					return err
+				}
+			}
+			if len(rec.OldImage) > 0 {
+				change.Old = PT(new(T))
+				if err := dynamox.UnmarshalMap(rec.OldImage, change.Old); err != nil {
+					return err
+				}
+			}
+			return fn(ctx, change)
+		},
+	})
+}
+
+// dispatch delivers rec to every matching handler in registration order
+func (p *Processor) dispatch(ctx context.Context, rec Record) error {
+	matched := false
+	for _, h := range p.handlers {
+		if !h.match(rec) {
+			continue
+		}
+		matched = true
+		if err := h.dispatch(ctx, rec); err != nil {
+			return err
+		}
+	}
+	if !matched && p.opts.Unmatched != nil {
+		return p.opts.Unmatched(ctx, rec)
+	}
+	return nil
+}
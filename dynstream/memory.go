@@ -0,0 +1,223 @@
+package dynstream
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	streamtypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+)
+
+// MemoryStream is an in-process stand-in for DynamoDB Streams, for tests.
+// Shards, splits and iterator expiry are driven explicitly by the caller.
+type MemoryStream struct {
+	mu         sync.Mutex
+	arn        string
+	shards     []*memShard
+	seq        int64
+	generation int // bumped by ExpireIterators
+}
+
+type memShard struct {
+	id      string
+	parent  string
+	records []streamtypes.Record
+	closed  bool
+}
+
+func NewMemoryStream(streamArn string) *MemoryStream {
+	return &MemoryStream{arn: streamArn}
+}
+
+func (ms *MemoryStream) StreamArn() string { return ms.arn }
+
+func (ms *MemoryStream) AddShard(shardID, parentID string) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.shards = append(ms.shards, &memShard{id: shardID, parent: parentID})
+}
+
+// Split closes parent and opens children with parent lineage
+func (ms *MemoryStream) Split(parentID string, childIDs ...string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	parent := ms.shard(parentID)
+	if parent == nil {
+		return ErrUnknownShard
+	}
+	parent.closed = true
+	for _, id := range childIDs {
+		ms.shards = append(ms.shards, &memShard{id: id, parent: parentID})
+	}
+	return nil
+}
+
+func (ms *MemoryStream) CloseShard(shardID string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	shard := ms.shard(shardID)
+	if shard == nil {
+		return ErrUnknownShard
+	}
+	shard.closed = true
+	return nil
+}
+
+// ExpireIterators invalidates every shard iterator handed out so far
+func (ms *MemoryStream) ExpireIterators() {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.generation++
+}
+
+// Put appends a record to an open shard and returns its sequence number
+func (ms *MemoryStream) Put(shardID string, typ EventType, keys, newImage, oldImage map[string]types.AttributeValue) (string, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	shard := ms.shard(shardID)
+	switch {
+	case shard == nil:
+		return "", ErrUnknownShard
+	case shard.closed:
+		return "", ErrShardClosed
+	}
+	ms.seq++
+	seq := fmt.Sprintf("%021d", ms.seq)
+	shard.records = append(shard.records, streamtypes.Record{
+		EventID:     aws.String(seq),
+		EventName:   streamtypes.OperationType(typ),
+		EventSource: aws.String("aws:dynamodb"),
+		Dynamodb: &streamtypes.StreamRecord{
+			ApproximateCreationDateTime: aws.Time(time.Now()),
+			Keys:                        toStreamImage(keys),
+			NewImage:                    toStreamImage(newImage),
+			OldImage:                    toStreamImage(oldImage),
+			SequenceNumber:              aws.String(seq),
+			StreamViewType:              streamtypes.StreamViewTypeNewAndOldImages,
+		},
+	})
+	return seq, nil
+}
+
+func (ms *MemoryStream) shard(id string) *memShard {
+	for _, v := range ms.shards {
+		if v.id == id {
+			return v
+		}
+	}
+	return nil
+}
+
+/////////////////////////////////////////////////////////////////////////////
+// StreamAPI
+
+func (ms *MemoryStream) DescribeStream(_ context.Context, params *dynamodbstreams.DescribeStreamInput, _ ...func(*dynamodbstreams.Options)) (*dynamodbstreams.DescribeStreamOutput, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if aws.ToString(params.StreamArn) != ms.arn {
+		return nil, &streamtypes.ResourceNotFoundException{Message: params.StreamArn}
+	}
+	shards := make([]streamtypes.Shard, 0, len(ms.shards))
+	for _, v := range ms.shards {
+		shard := streamtypes.Shard{ShardId: aws.String(v.id), SequenceNumberRange: &streamtypes.SequenceNumberRange{}}
+		if v.parent != "" {
+			shard.ParentShardId = aws.String(v.parent)
+		}
+		if n := len(v.records); n > 0 {
+			shard.SequenceNumberRange.StartingSequenceNumber = v.records[0].Dynamodb.SequenceNumber
+			if v.closed {
+				shard.SequenceNumberRange.EndingSequenceNumber = v.records[n-1].Dynamodb.SequenceNumber
+			}
+		}
+		shards = append(shards, shard)
+	}
+	return &dynamodbstreams.DescribeStreamOutput{StreamDescription: &streamtypes.StreamDescription{
+		StreamArn:    aws.String(ms.arn),
+		StreamStatus: streamtypes.StreamStatusEnabled,
+		Shards:       shards,
+	}}, nil
+}
+
+func (ms *MemoryStream) GetShardIterator(_ context.Context, params *dynamodbstreams.GetShardIteratorInput, _ ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetShardIteratorOutput, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	shard := ms.shard(aws.ToString(params.ShardId))
+	if shard == nil {
+		return nil, &streamtypes.ResourceNotFoundException{Message: params.ShardId}
+	}
+
+	pos := 0
+	switch params.ShardIteratorType {
+	case streamtypes.ShardIteratorTypeTrimHorizon:
+	case streamtypes.ShardIteratorTypeLatest:
+		pos = len(shard.records)
+	case streamtypes.ShardIteratorTypeAtSequenceNumber, streamtypes.ShardIteratorTypeAfterSequenceNumber:
+		seq := aws.ToString(params.SequenceNumber)
+		pos = -1
+		for i, v := range shard.records {
+			if aws.ToString(v.Dynamodb.SequenceNumber) == seq {
+				pos = i
+				break
+			}
+		}
+		if pos < 0 {
+			return nil, &streamtypes.TrimmedDataAccessException{Message: params.SequenceNumber}
+		}
+		if params.ShardIteratorType == streamtypes.ShardIteratorTypeAfterSequenceNumber {
+			pos++
+		}
+	default:
+		return nil, ErrInvalidShardIterator
+	}
+	return &dynamodbstreams.GetShardIteratorOutput{ShardIterator: aws.String(ms.iterator(shard.id, pos))}, nil
+}
+
+func (ms *MemoryStream) GetRecords(_ context.Context, params *dynamodbstreams.GetRecordsInput, _ ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetRecordsOutput, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	shardID, pos, generation, err := parseMemIterator(aws.ToString(params.ShardIterator))
+	if err != nil {
+		return nil, err
+	}
+	if generation != ms.generation {
+		return nil, &streamtypes.ExpiredIteratorException{Message: params.ShardIterator}
+	}
+	shard := ms.shard(shardID)
+	if shard == nil {
+		return nil, &streamtypes.ResourceNotFoundException{Message: aws.String(shardID)}
+	}
+
+	end := len(shard.records)
+	if limit := int(aws.ToInt32(params.Limit)); limit > 0 && pos+limit < end {
+		end = pos + limit
+	}
+	out := &dynamodbstreams.GetRecordsOutput{Records: append([]streamtypes.Record(nil), shard.records[pos:end]...)}
+	if !shard.closed || end < len(shard.records) {
+		out.NextShardIterator = aws.String(ms.iterator(shard.id, end))
+	}
+	return out, nil
+}
+
+func (ms *MemoryStream) iterator(shardID string, pos int) string {
+	return strings.Join([]string{shardID, strconv.Itoa(pos), strconv.Itoa(ms.generation)}, "|")
+}
+
+func parseMemIterator(it string) (shardID string, pos, generation int, err error) {
+	split := strings.Split(it, "|")
+	if len(split) != 3 {
+		return "", 0, 0, ErrInvalidShardIterator
+	}
+	if pos, err = strconv.Atoi(split[1]); err != nil {
+		return "", 0, 0, ErrInvalidShardIterator
+	}
+	if generation, err = strconv.Atoi(split[2]); err != nil {
+		return "", 0, 0, ErrInvalidShardIterator
+	}
+	return split[0], pos, generation, nil
+}
@@ -0,0 +1,313 @@
+package dynstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	streamtypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+)
+
+// StreamAPI is the subset of *dynamodbstreams.Client used by Processor,
+// satisfied by MemoryStream as a local stand-in.
+type StreamAPI interface {
+	DescribeStream(ctx context.Context, params *dynamodbstreams.DescribeStreamInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.DescribeStreamOutput, error)
+	GetShardIterator(ctx context.Context, params *dynamodbstreams.GetShardIteratorInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetShardIteratorOutput, error)
+	GetRecords(ctx context.Context, params *dynamodbstreams.GetRecordsInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetRecordsOutput, error)
+}
+
+var (
+	_ StreamAPI = (*dynamodbstreams.Client)(nil)
+	_ StreamAPI = (*MemoryStream)(nil)
+)
+
+type Options struct {
+	PollInterval    time.Duration // sleep when no shard returned records, default 1s
+	RefreshInterval time.Duration // re-describe the stream to find new shards, default 30s
+	Limit           int32         // GetRecords limit, default 1000 (max)
+	// position for shards open at startup without checkpoint, TRIM_HORIZON (default) or LATEST;
+	// shards split off later, or whose parent is known, always start at TRIM_HORIZON
+	StartingPosition streamtypes.ShardIteratorType
+	// called for records no handler matched, ignored if nil
+	Unmatched func(ctx context.Context, rec Record) error
+	// called when a shard's checkpoint is older than the stream's 24h retention: the records after it
+	// are lost. nil error resumes the shard at TRIM_HORIZON; if Trimmed is nil, Step fails with ErrCheckpointTrimmed
+	Trimmed func(ctx context.Context, shardID, sequenceNumber string) error
+}
+
+type shardState struct {
+	shard    streamtypes.Shard
+	iterator *string
+	lastSeq  string
+	finished bool
+	child    bool // discovered after startup or with a known parent, read from TRIM_HORIZON
+}
+
+func (s *shardState) id() string     { return aws.ToString(s.shard.ShardId) }
+func (s *shardState) parent() string { return aws.ToString(s.shard.ParentShardId) }
+
+// Processor walks every shard of a stream, parents before children, delivers
+// records to the registered handlers and checkpoints after each batch.
+// Delivery is at-least-once: a batch whose handler fails is retried after restart.
+type Processor struct {
+	api        StreamAPI
+	streamArn  string
+	checkpoint Checkpointer
+	opts       Options
+	handlers   []handler
+
+	shards      map[string]*shardState
+	order       []string        // discovery order
+	pruned      map[string]bool // finished shards dropped from shards, while the stream still lists them
+	refreshedAt time.Time
+	refresh     bool
+	described   bool // the shards open at startup are known
+}
+
+func NewProcessor(api StreamAPI, streamArn string, checkpoint Checkpointer, optFns ...func(*Options)) *Processor {
+	opts := Options{
+		PollInterval:     time.Second,
+		RefreshInterval:  30 * time.Second,
+		Limit:            1000,
+		StartingPosition: streamtypes.ShardIteratorTypeTrimHorizon,
+	}
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+	return &Processor{
+		api:        api,
+		streamArn:  streamArn,
+		checkpoint: checkpoint,
+		opts:       opts,
+		shards:     make(map[string]*shardState),
+		pruned:     make(map[string]bool),
+		refresh:    true,
+	}
+}
+
+// Run polls until ctx is done or a handler/API error occurs; returns ctx.Err() on cancellation
+func (p *Processor) Run(ctx context.Context) error {
+	for {
+		n, err := p.Step(ctx)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(p.opts.PollInterval):
+		}
+	}
+}
+
+// Step performs one GetRecords round over every readable shard and returns the number of records delivered
+func (p *Processor) Step(ctx context.Context) (int, error) {
+	switch {
+	case p.streamArn == "":
+		return 0, ErrRequiredStreamArn
+	case p.checkpoint == nil:
+		return 0, ErrRequiredCheckpointer
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if p.refresh || time.Since(p.refreshedAt) >= p.opts.RefreshInterval {
+		if err := p.describe(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	total := 0
+	for _, id := range p.order {
+		state := p.shards[id]
+		if state.finished || !p.parentFinished(state) {
+			continue
+		}
+		n, err := p.poll(ctx, state)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// Shards returns the ids of the shards the processor tracks, in discovery order;
+// finished shards are dropped once their children are known
+func (p *Processor) Shards() []string { return slices.Clone(p.order) }
+
+// parent must be drained first to keep per-item ordering across a split;
+// an unknown parent has been trimmed from the stream
+func (p *Processor) parentFinished(state *shardState) bool {
+	parent, exist := p.shards[state.parent()]
+	return !exist || parent.finished
+}
+
+func (p *Processor) describe(ctx context.Context) error {
+	var (
+		input  = &dynamodbstreams.DescribeStreamInput{StreamArn: aws.String(p.streamArn)}
+		added  []*shardState
+		listed = make(map[string]bool)
+	)
+	for {
+		out, err := p.api.DescribeStream(ctx, input)
+		if err != nil {
+			return err
+		}
+		if out.StreamDescription == nil {
+			break
+		}
+		for _, shard := range out.StreamDescription.Shards {
+			id := aws.ToString(shard.ShardId)
+			listed[id] = true
+			if p.pruned[id] {
+				continue
+			}
+			if state, exist := p.shards[id]; exist {
+				state.shard = shard
+				continue
+			}
+			cp, err := p.checkpoint.Load(ctx, p.streamArn, id)
+			if err != nil {
+				return err
+			}
+			state := &shardState{shard: shard, lastSeq: cp.SequenceNumber, finished: cp.Finished}
+			p.shards[id] = state
+			p.order = append(p.order, id)
+			added = append(added, state)
+		}
+		if out.StreamDescription.LastEvaluatedShardId == nil {
+			break
+		}
+		input.ExclusiveStartShardId = out.StreamDescription.LastEvaluatedShardId
+	}
+	// records of a split child are written before it's found, LATEST would skip them
+	for _, state := range added {
+		_, parentKnown := p.shards[state.parent()]
+		state.child = p.described || parentKnown
+	}
+	p.prune(listed)
+	p.described = true
+	p.refresh = false
+	p.refreshedAt = time.Now()
+	return nil
+}
+
+// prune drops finished shards whose children are scheduled, or which the stream no longer lists,
+// so that a long-running processor keeps only the live part of the shard lineage
+func (p *Processor) prune(listed map[string]bool) {
+	for id := range p.pruned {
+		if !listed[id] {
+			delete(p.pruned, id)
+		}
+	}
+	parents := make(map[string]bool, len(p.shards))
+	for _, state := range p.shards {
+		parents[state.parent()] = true
+	}
+	p.order = slices.DeleteFunc(p.order, func(id string) bool {
+		state := p.shards[id]
+		if !state.finished || (!parents[id] && listed[id]) {
+			return false
+		}
+		delete(p.shards, id)
+		if listed[id] {
+			p.pruned[id] = true
+		}
+		return true
+	})
+}
+
+func (p *Processor) iterator(ctx context.Context, state *shardState) (*string, error) {
+	input := &dynamodbstreams.GetShardIteratorInput{
+		StreamArn:         aws.String(p.streamArn),
+		ShardId:           state.shard.ShardId,
+		ShardIteratorType: p.opts.StartingPosition,
+	}
+	switch {
+	case state.lastSeq != "":
+		input.ShardIteratorType = streamtypes.ShardIteratorTypeAfterSequenceNumber
+		input.SequenceNumber = aws.String(state.lastSeq)
+	case state.child:
+		input.ShardIteratorType = streamtypes.ShardIteratorTypeTrimHorizon
+	}
+	out, err := p.api.GetShardIterator(ctx, input)
+	var trimmed *streamtypes.TrimmedDataAccessException
+	if errors.As(err, &trimmed) && state.lastSeq != "" {
+		// checkpoint is older than the 24h retention, continue from the oldest record if allowed
+		if p.opts.Trimmed == nil {
+			return nil, fmt.Errorf("%w: shard %s after %s", ErrCheckpointTrimmed, state.id(), state.lastSeq)
+		}
+		if err = p.opts.Trimmed(ctx, state.id(), state.lastSeq); err != nil {
+			return nil, err
+		}
+		input.ShardIteratorType = streamtypes.ShardIteratorTypeTrimHorizon
+		input.SequenceNumber = nil
+		out, err = p.api.GetShardIterator(ctx, input)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out.ShardIterator, nil
+}
+
+func (p *Processor) getRecords(ctx context.Context, state *shardState) (*dynamodbstreams.GetRecordsOutput, error) {
+	for retried := false; ; retried = true {
+		if state.iterator == nil {
+			iter, err := p.iterator(ctx, state)
+			if err != nil {
+				return nil, err
+			}
+			state.iterator = iter
+		}
+		out, err := p.api.GetRecords(ctx, &dynamodbstreams.GetRecordsInput{
+			ShardIterator: state.iterator,
+			Limit:         aws.Int32(p.opts.Limit),
+		})
+		var expired *streamtypes.ExpiredIteratorException
+		if errors.As(err, &expired) && !retried {
+			// iterators live 15 minutes, resume after the last processed record
+			state.iterator = nil
+			continue
+		}
+		return out, err
+	}
+}
+
+func (p *Processor) poll(ctx context.Context, state *shardState) (int, error) {
+	out, err := p.getRecords(ctx, state)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, v := range out.Records {
+		if err = p.dispatch(ctx, newRecord(p.streamArn, state.id(), v)); err != nil {
+			return 0, err
+		}
+	}
+	if n := len(out.Records); n > 0 {
+		state.lastSeq = aws.ToString(out.Records[n-1].Dynamodb.SequenceNumber)
+		if err = p.checkpoint.Save(ctx, p.streamArn, state.id(), Checkpoint{SequenceNumber: state.lastSeq}); err != nil {
+			return 0, err
+		}
+	}
+
+	state.iterator = out.NextShardIterator
+	if state.iterator == nil {
+		return len(out.Records), p.finish(ctx, state)
+	}
+	return len(out.Records), nil
+}
+
+func (p *Processor) finish(ctx context.Context, state *shardState) error {
+	state.finished = true
+	p.refresh = true // children of a closed shard appear in the next describe
+	return p.checkpoint.Save(ctx, p.streamArn, state.id(), Checkpoint{SequenceNumber: state.lastSeq, Finished: true})
+}
@@ -5,4 +5,6 @@ const (
 	BatchGetLimit         = 100
 	TransactionWriteLimit = 25
 	TransactionGetLimit   = 100
+
+	IndexNonKeyAttributesLimit = 100 // INCLUDE, summed across the indexes of a table
 )
@@ -27,10 +27,32 @@ var (
 	ErrUnsupportedAttrValueTypeForKey = errors.New("unsupported AttributeValue type for key")
 	ErrBeginsWithPrefixType           = errors.New("beginsWith prefix must be string")
 	ErrBetweenUpperValue              = errors.New("between must have secondary value")
+	ErrUnexpectedConditionOperator    = errors.New("unexpected condition operator")
 	ErrUnembedModel                   = errors.New("unembeded dynamodel.Model")
 	ErrNotFoundItem                   = errors.New("not found item")
 	ErrEmptyForUpdate                 = errors.New("empty for update")
 	ErrInvalidAttributeDefinition     = errors.New("invalid attribute definition")
 	ErrOutMustBePointerToSlice        = errors.New("out must be pointer to slice")
 	ErrUnprocessedItems               = errors.New("check UnprocessedItems")
+	ErrWriteEventHandler              = errors.New("write event handler failed")
+	ErrRequiredEntityRegistry         = errors.New("required EntityRegistry")
+	ErrUnexpectedAttributeType        = errors.New("unexpected attribute type")
+	ErrInvalidKeyTag                  = errors.New("invalid dynamox key tag")
+	ErrUnknownIDGenerator             = errors.New("unknown id generator")
+	ErrUnderivableProjection          = errors.New("cannot derive projection from type")
+	ErrUnsupportedKeyCondition        = errors.New("unsupported key condition for the key type")
+	ErrConsistentReadOnGSI            = errors.New("consistentRead is unsupported on GSI")
+	ErrInvalidIndexProjection         = errors.New("invalid index projection")
+	ErrInvalidIndexThroughput         = errors.New("invalid index throughput")
+	ErrUnderivableTableKey            = errors.New("cannot derive table key")
+	ErrInvalidTokenKey                = errors.New("invalid pagination token key")
+	ErrUnknownTokenKey                = errors.New("unknown pagination token key")
+	ErrMalformedPaginationToken       = errors.New("malformed pagination token")
+	ErrForgedPaginationToken          = errors.New("pagination token forged or bound to another query")
+	ErrUnsupportedTokenVersion        = errors.New("unsupported pagination token version")
+	ErrTruncatedPaginationToken       = errors.New("truncated pagination token")
+	ErrInvalidPaginationKey           = errors.New("invalid pagination key")
+	ErrIDSeqTimeOutOfRange            = errors.New("time out of IDSeq range")
+	ErrIDSeqOverflow                  = errors.New("IDSeq entropy exhausted within the millisecond")
+	ErrTooManyInOperands              = errors.New("too many IN operands")
 )
@@ -0,0 +1,153 @@
+package dynamox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type writeOp int
+
+const (
+	OpCreate writeOp = iota
+	OpUpdate
+	OpDelete
+	OpDeleteSoft
+)
+
+func (op writeOp) String() string {
+	switch op {
+	case OpCreate:
+		return "create"
+	case OpUpdate:
+		return "update"
+	case OpDelete:
+		return "delete"
+	default:
+		return "deleteSoft"
+	}
+}
+
+// WriteEvent is published synchronously by cruder after a successful write
+type WriteEvent struct {
+	Op     writeOp
+	Entity reflect.Type // struct type of the KeyedItem
+	Table  string
+	Key    map[string]types.AttributeValue
+	New    KeyedItem // item as written on OpCreate, as stored (ReturnValues ALL_NEW) on OpUpdate/OpDeleteSoft, nil on OpDelete
+	Old    KeyedItem // previous item (ReturnValues ALL_OLD) on OpCreate/OpDelete, nil if none existed or on updates
+}
+
+type WriteHandler func(ctx context.Context, ev WriteEvent) error
+
+type writeSubscription struct {
+	fn WriteHandler
+}
+
+type writeBus struct {
+	mu   sync.RWMutex
+	subs map[reflect.Type][]*writeSubscription
+}
+
+func newWriteBus() *writeBus {
+	return &writeBus{subs: make(map[reflect.Type][]*writeSubscription)}
+}
+
+// Subscribe registers fn for writes of entity's type through Cruder, e.g. Subscribe((*Profile)(nil), fn).
+// handlers run synchronously in registration order; their errors are returned
+// from the cruder call wrapped with ErrWriteEventHandler, after the write is applied.
+func (c *Client) Subscribe(entity KeyedItem, fn WriteHandler) (unsubscribe func()) {
+	typ := entityTypeOf(entity)
+	sub := &writeSubscription{fn: fn}
+
+	c.bus.mu.Lock()
+	c.bus.subs[typ] = append(c.bus.subs[typ], sub)
+	c.bus.mu.Unlock()
+
+	return func() {
+		c.bus.mu.Lock()
+		defer c.bus.mu.Unlock()
+		subs := c.bus.subs[typ]
+		for i, v := range subs {
+			if v == sub {
+				c.bus.subs[typ] = append(subs[:i:i], subs[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func (b *writeBus) subscribers(typ reflect.Type) []*writeSubscription {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.subs[typ]
+}
+
+func (b *writeBus) hasSubscriber(item KeyedItem) bool {
+	return len(b.subscribers(entityTypeOf(item))) > 0
+}
+
+func (b *writeBus) publish(ctx context.Context, ev WriteEvent) error {
+	var errs []error
+	for _, v := range b.subscribers(ev.Entity) {
+		if err := v.fn(ctx, ev); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %s %s: %w", ErrWriteEventHandler, ev.Op, ev.Entity, errors.Join(errs...))
+	}
+	return nil
+}
+
+// publishWrite publishes keyedItem as New, old decoded as Old; empty old means no previous item
+func (b *writeBus) publishWrite(ctx context.Context, op writeOp, keyedItem KeyedItem, key map[string]types.AttributeValue, old attrMap) error {
+	ev := WriteEvent{
+		Op:     op,
+		Entity: entityTypeOf(keyedItem),
+		Table:  keyedItem.Table(),
+		Key:    key,
+	}
+	if op != OpDelete {
+		ev.New = keyedItem
+	}
+	var err error
+	if ev.Old, err = decodeEventItem(keyedItem, old); err != nil {
+		return err
+	}
+	return b.publish(ctx, ev)
+}
+
+// publishUpdate publishes the item stored after an update, decoded out of ReturnValues ALL_NEW
+func (b *writeBus) publishUpdate(ctx context.Context, op writeOp, keyedItem KeyedItem, key map[string]types.AttributeValue, stored attrMap) error {
+	ev := WriteEvent{
+		Op:     op,
+		Entity: entityTypeOf(keyedItem),
+		Table:  keyedItem.Table(),
+		Key:    key,
+	}
+	var err error
+	if ev.New, err = decodeEventItem(keyedItem, stored); err != nil {
+		return err
+	}
+	return b.publish(ctx, ev)
+}
+
+// decodeEventItem decodes attrs into a new item of keyedItem's type, nil for empty attrs
+func decodeEventItem(keyedItem KeyedItem, attrs attrMap) (KeyedItem, error) {
+	if len(attrs) == 0 {
+		return nil, nil
+	}
+	item, ok := newKeyedItemOf(keyedItem)
+	if !ok {
+		return nil, nil
+	}
+	if err := UnmarshalMap(attrs, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
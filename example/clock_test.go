@@ -0,0 +1,63 @@
+package example
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-chujang/dynamox"
+)
+
+func Test_clock(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clock := dynamox.NewFakeClock(at)
+	dynamox.SetClock(clock)
+	defer dynamox.SetClock(nil)
+
+	if !dynamox.TimeNow().Time().Equal(at) {
+		t.Fatal("TimeNow ignores the global clock")
+	}
+	av, err := attributevalue.Marshal(dynamox.TimeDefaultNow(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, ok := av.(*types.AttributeValueMemberN); !ok || n.Value != "1714564800000" {
+		t.Fatalf("unexpected TimeDefaultNow: %v", av)
+	}
+	var m dynamox.Model
+	clock.Advance(time.Minute)
+	if !m.SetUpdatedAt().UpdatedAt.Time().Equal(at.Add(time.Minute)) {
+		t.Fatal("SetUpdatedAt ignores the global clock")
+	}
+
+	// the context's clock wins over the global one
+	ctxClock := dynamox.NewFakeClock(at.Add(24 * time.Hour))
+	ctx := dynamox.WithClock(t.Context(), ctxClock)
+	if !dynamox.TimeNowCtx(ctx).Time().Equal(ctxClock.Now()) {
+		t.Fatal("TimeNowCtx ignores the context clock")
+	}
+
+	rec := &recorder{}
+	c := newRecordedClient(rec)
+	item := &event{base: base{CustomerId: "123", Sk: "EVENT#1"}}
+	if err = c.Cruder().Create(ctx, item, false); err != nil {
+		t.Fatal(err)
+	}
+	if !item.CreatedAt.Time().Equal(ctxClock.Now()) {
+		t.Fatalf("unexpected createdAt: %v", item.CreatedAt.Time())
+	}
+	if err = c.Cruder().DeleteSoft(ctx, item); err != nil {
+		t.Fatal(err)
+	}
+	values, _ := rec.bodies[1]["ExpressionAttributeValues"].(map[string]any)
+	var found bool
+	for _, v := range values {
+		if n, _ := v.(map[string]any)["N"].(string); n == "1714651200000" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("deletedAt not from the context clock: %v", values)
+	}
+}
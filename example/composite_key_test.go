@@ -0,0 +1,204 @@
+package example
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-chujang/dynamox"
+)
+
+type order struct {
+	CustomerId string `dynamodbav:"customerId"`
+	Sk         string `dynamodbav:"sk"`
+	Status     string `dynamodbav:"status"`
+	Year       int    `dynamodbav:"year"`
+	OrderId    string `dynamodbav:"orderId"`
+}
+
+var orderSK = dynamox.NewCompositeKeySchema("ORDER", dynamox.Seg("status"), dynamox.Seg("year"), dynamox.Seg("OrderId"))
+
+func Test_compositeKey(t *testing.T) {
+	o := order{CustomerId: "123", Status: "SHIPPED", Year: 2024, OrderId: "A1"}
+	sk, err := orderSK.BuildFrom(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sk != "ORDER#SHIPPED#2024#A1" {
+		t.Fatalf("unexpected key: %s", sk)
+	}
+	if built, _ := orderSK.Build("SHIPPED", 2024, "A1"); built != sk {
+		t.Fatalf("unexpected build: %s", built)
+	}
+
+	var parsed order
+	if err = orderSK.Parse(sk, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Status != o.Status || parsed.Year != o.Year || parsed.OrderId != o.OrderId {
+		t.Fatalf("unexpected parse: %+v", parsed)
+	}
+
+	for values, expected := range map[int]string{0: "ORDER#", 1: "ORDER#SHIPPED#", 2: "ORDER#SHIPPED#2024#", 3: sk} {
+		prefix, err := orderSK.Prefix([]any{"SHIPPED", 2024, "A1"}[:values]...)
+		if err != nil || prefix != expected {
+			t.Fatalf("unexpected prefix(%d): %s, %v", values, prefix, err)
+		}
+	}
+	prefix, _ := orderSK.Prefix("SHIPPED")
+	if _, err = dynamox.NewKeyCondBuilder().WithPK("customerId", o.CustomerId).WithSK(dynamox.BeginsWith, "sk", prefix).Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = orderSK.Parse("ORDER#SHIPPED#2024", &parsed); !errors.Is(err, dynamox.ErrinsufficientParseCompositeKey) {
+		t.Fatalf("expected ErrinsufficientParseCompositeKey, got %v", err)
+	}
+	if err = orderSK.Parse("ORDER#SHIPPED#twenty#A1", &parsed); !errors.Is(err, dynamox.ErrinsufficientParseCompositeKey) {
+		t.Fatalf("expected ErrinsufficientParseCompositeKey, got %v", err)
+	}
+	if _, err = orderSK.BuildFrom(order{Status: "SHIPPED"}); !errors.Is(err, dynamox.ErrInsufficientCompositeKeySrc) {
+		t.Fatalf("expected ErrInsufficientCompositeKeySrc, got %v", err)
+	}
+}
+
+func Test_compositeKeyEscape(t *testing.T) {
+	bookmarkSK := dynamox.NewCompositeKeySchema("BM", dynamox.Seg("url"), dynamox.Seg("title")).Escaped()
+	var (
+		url   = "https://aws.amazon.com/#pricing"
+		title = "$5 #1"
+	)
+	sk, err := bookmarkSK.Build(url, title)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts, err := bookmarkSK.Split(sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parts[0] != url || parts[1] != title {
+		t.Fatalf("unexpected split: %q", parts)
+	}
+	// plain keys are unchanged, escaping is opt-in
+	if key := profileSortKeyPrefix.Composite("a$b", "c"); key != "CUST#a$b#c" || !slices.Equal(dynamox.SplitCompositeKey(key), []string{"CUST", "a$b", "c"}) {
+		t.Fatalf("unexpected plain key: %q", key)
+	}
+	if _, err = bookmarkSK.Split(dynamox.SortKeyPrefix("BM").Composite(url, title)); err == nil {
+		t.Fatal("expected the plain key of a separator to split ambiguously")
+	}
+	plainSK, _ := dynamox.NewCompositeKeySchema("BM", dynamox.Seg("url"), dynamox.Seg("title")).Build("https://aws.amazon.com/", "$5")
+	if plainSK != "BM#https://aws.amazon.com/#$5" {
+		t.Fatalf("unexpected plain key: %q", plainSK)
+	}
+
+	// lexicographic order within a segment is kept
+	values := []string{"a", "a#", "a##", "a#b", "a$", "a$#", "a%", "a\"", "b"}
+	sort.Strings(values)
+	keys := make([]string, len(values))
+	urlSK := dynamox.NewCompositeKeySchema("BM", dynamox.Seg("url")).Escaped()
+	for i, v := range values {
+		keys[i], _ = urlSK.Build(v)
+	}
+	if !sort.StringsAreSorted(keys) {
+		t.Fatalf("unexpected order: %q", keys)
+	}
+}
+
+func Test_sortableSegment(t *testing.T) {
+	ints := []int64{math.MinInt64, -1000, -10, -9, -1, 0, 1, 9, 10, 1000, math.MaxInt64}
+	floats := []float64{math.Inf(-1), -1e10, -2.5, -0.5, 0, 0.5, 2.5, 1e10, math.Inf(1)}
+	now := time.Now()
+	times := []time.Time{now.Add(-time.Hour), now.Add(-time.Millisecond), now, now.Add(time.Nanosecond), now.Add(24 * time.Hour)}
+
+	sorted := func(keys []string) bool { return sort.StringsAreSorted(keys) }
+	encode := func(n int, fn func(i int) string) []string {
+		keys := make([]string, n)
+		for i := range n {
+			keys[i] = profileSortKeyPrefix.Composite(fn(i))
+		}
+		return keys
+	}
+	if !sorted(encode(len(ints), func(i int) string { return dynamox.SortableInt(ints[i]) })) {
+		t.Fatal("SortableInt out of order")
+	}
+	if !sorted(encode(len(floats), func(i int) string { return dynamox.SortableFloat(floats[i]) })) {
+		t.Fatal("SortableFloat out of order")
+	}
+	if !sorted(encode(len(times), func(i int) string { return dynamox.SortableTime(times[i]) })) {
+		t.Fatal("SortableTime out of order")
+	}
+	reversed := encode(len(times), func(i int) string { return dynamox.ReverseTime(times[i]) })
+	slices.Reverse(reversed)
+	if !sorted(reversed) {
+		t.Fatal("ReverseTime out of order")
+	}
+	if !sorted(encode(3, func(i int) string { return dynamox.PadInt([]uint64{9, 10, 100}[i], 5) })) {
+		t.Fatal("PadInt out of order")
+	}
+
+	for _, v := range ints {
+		if n, err := dynamox.ParseSortableInt(dynamox.SortableInt(v)); err != nil || n != v {
+			t.Fatalf("unexpected SortableInt roundtrip %d: %d, %v", v, n, err)
+		}
+	}
+	for _, v := range floats {
+		if f, err := dynamox.ParseSortableFloat(dynamox.SortableFloat(v)); err != nil || f != v {
+			t.Fatalf("unexpected SortableFloat roundtrip %v: %v, %v", v, f, err)
+		}
+	}
+	if at, err := dynamox.ParseReverseTime(dynamox.ReverseTime(now)); err != nil || !at.Equal(now) {
+		t.Fatalf("unexpected ReverseTime roundtrip: %v, %v", at, err)
+	}
+	if dynamox.SortableFloat(math.Copysign(0, -1)) != dynamox.SortableFloat(0) {
+		t.Fatal("-0 and +0 encoded apart")
+	}
+	// pre-1970 and zero times clamp to the epoch instead of overflowing, the segment rejects them
+	epoch := dynamox.ReverseTime(time.Unix(0, 0))
+	for _, out := range []time.Time{{}, time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC)} {
+		if got := dynamox.ReverseTime(out); got != epoch {
+			t.Fatalf("%v: unexpected ReverseTime %s", out, got)
+		}
+		if _, err := dynamox.ReverseTimeSegment.EncodeSegment(reflect.ValueOf(out)); err == nil {
+			t.Fatalf("%v: expected out of range", out)
+		}
+	}
+	if enc, err := dynamox.SortableFloatSegment.EncodeSegment(reflect.ValueOf(uint32(7))); err != nil || enc != dynamox.SortableFloat(7) {
+		t.Fatalf("unexpected uint SortableFloatSegment: %s, %v", enc, err)
+	}
+	var u uint16
+	if err := dynamox.SortableFloatSegment.DecodeSegment(dynamox.SortableFloat(7), reflect.ValueOf(&u).Elem()); err != nil || u != 7 {
+		t.Fatalf("unexpected uint decode: %d, %v", u, err)
+	}
+
+	type score struct {
+		Score int64     `dynamodbav:"score"`
+		At    time.Time `dynamodbav:"at"`
+	}
+	scoreSK := dynamox.NewCompositeKeySchema("SCORE", dynamox.Seg("score", dynamox.SortableIntSegment), dynamox.Seg("at", dynamox.SortableTimeSegment))
+	sk, err := scoreSK.BuildFrom(score{Score: -42, At: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed score
+	if err = scoreSK.Parse(sk, &parsed); err != nil || parsed.Score != -42 || !parsed.At.Equal(now) {
+		t.Fatalf("unexpected parse: %+v, %v", parsed, err)
+	}
+	lower, _ := scoreSK.Prefix(int64(-100))
+	upper, _ := scoreSK.UpperBound(int64(100))
+	if _, err = dynamox.NewKeyCondBuilder().WithPK("customerId", "123").WithSK(dynamox.Between, "sk", lower, upper).Build(); err != nil {
+		t.Fatal(err)
+	}
+	if !(lower < sk && sk < upper) {
+		t.Fatal("sk out of between bounds")
+	}
+	// the upper score itself is within the bounds, at any time
+	if edge, _ := scoreSK.Build(int64(100), now.Add(time.Hour)); !(edge < upper) {
+		t.Fatalf("score 100 out of between bounds: %s, %s", edge, upper)
+	}
+	if past, _ := scoreSK.Build(int64(101), now); past < upper {
+		t.Fatal("score 101 within between bounds")
+	}
+}
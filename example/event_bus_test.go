@@ -0,0 +1,76 @@
+package example
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-chujang/dynamox"
+)
+
+func Test_eventbus(t *testing.T) {
+	keybase := base{CustomerId: "eventbus"}
+	ensureCustomerBookmark(t)
+	// the bookmark, and the profile when the test fails before deleting it
+	t.Cleanup(func() {
+		_ = cli.Cruder().Delete(context.Background(), &profile{base: keybase})
+		_ = cli.Cruder().Delete(context.Background(), &bookmark{base: keybase, Url: "https://aws.amazon.com"})
+	})
+
+	var events []dynamox.WriteEvent
+	unsubscribe := cli.Subscribe((*profile)(nil), func(_ context.Context, ev dynamox.WriteEvent) error {
+		events = append(events, ev)
+		return nil
+	})
+	defer unsubscribe()
+
+	item := &profile{base: keybase, Email: "v1@example.net"}
+	if err := cli.Cruder().Create(t.Context(), item, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.Cruder().Update(t.Context(), &profile{base: keybase, Email: "v2@example.net"}, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.Cruder().Delete(t.Context(), &profile{base: keybase}); err != nil {
+		t.Fatal(err)
+	}
+	// bookmark has no subscriber
+	if err := cli.Cruder().Create(t.Context(), &bookmark{base: keybase, Url: "https://aws.amazon.com"}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+	if events[1].Op != dynamox.OpUpdate || events[1].New.(*profile).Email != "v2@example.net" || events[1].Old != nil {
+		t.Fatal("unexpected update event")
+	}
+	if events[2].Op != dynamox.OpDelete || events[2].New != nil || events[2].Old.(*profile).Email != "v2@example.net" {
+		t.Fatal("unexpected delete event")
+	}
+}
+
+func Test_writeEventUpdate(t *testing.T) {
+	rec := &recorder{responses: []string{`{"Attributes":{
+		"customerId":{"S":"123"},"sk":{"S":"CUST#123"},"email":{"S":"v2@example.net"},"fullName":{"S":"Shirley"}}}`}}
+	c := newRecordedClient(rec)
+	var events []dynamox.WriteEvent
+	c.Subscribe((*profile)(nil), func(_ context.Context, ev dynamox.WriteEvent) error {
+		events = append(events, ev)
+		return nil
+	})
+
+	// a partial update publishes the item as stored
+	if err := c.Cruder().Update(t.Context(), &profile{base: base{CustomerId: "123"}, Email: "v2@example.net"}, true); err != nil {
+		t.Fatal(err)
+	}
+	if rv := rec.bodies[0]["ReturnValues"]; rv != string(types.ReturnValueAllNew) {
+		t.Fatalf("unexpected ReturnValues: %v", rv)
+	}
+	if len(events) != 1 || events[0].Old != nil {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if stored := events[0].New.(*profile); stored.Email != "v2@example.net" || stored.Fullname != "Shirley" {
+		t.Fatalf("unexpected New: %+v", stored)
+	}
+}
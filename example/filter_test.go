@@ -0,0 +1,83 @@
+package example
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-chujang/dynamox"
+)
+
+func Test_filter(t *testing.T) {
+	filter := dynamox.And(
+		dynamox.Attr("status").In("PAID", "SHIPPED"),
+		dynamox.Or(dynamox.Attr("total").Ge(100), dynamox.Attr("tags").Contains("vip")),
+		dynamox.Attr("items").Size().Gt(0),
+		dynamox.Attr("note").Type(dynamox.AttrTypeString),
+		dynamox.Not(dynamox.Attr("deletedAt").Exists()),
+	)
+	kcb := dynamox.NewKeyCondBuilder().
+		WithPK("customerId", "123").
+		WithSK(dynamox.BeginsWith, "sk", "ORDER#").
+		WithProj("customerId", "sk", "status").
+		WithFilter(filter)
+	expr, err := kcb.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expr.KeyCondition() == nil || expr.Filter() == nil || expr.Projection() == nil {
+		t.Fatal("expected key condition, filter and projection")
+	}
+	for _, s := range []string{"IN (", "contains (", "size (", "attribute_type (", "NOT (attribute_exists ("} {
+		if !strings.Contains(*expr.Filter(), s) {
+			t.Fatalf("%q not in %s", s, *expr.Filter())
+		}
+	}
+	// one placeholder per name, one per value across key condition and filter
+	names := map[string]bool{}
+	for _, name := range expr.Names() {
+		if names[name] {
+			t.Fatalf("duplicated name placeholder of %s", name)
+		}
+		names[name] = true
+	}
+	if !names["customerId"] || !names["status"] || !names["deletedAt"] {
+		t.Fatalf("unexpected names: %v", expr.Names())
+	}
+	if l := len(expr.Values()); l != 8 { // 123, ORDER#, PAID, SHIPPED, 100, vip, 0, S
+		t.Fatalf("unexpected values: %d", l)
+	}
+
+	rec := &recorder{}
+	query := dynamox.NewCtxQuery(t.Context()).SetTable("CustomerBookmark").SetKeyCondBuilder(kcb)
+	if _, _, err = newRecordedClient(rec).Query(query, &[]base{}); err != nil {
+		t.Fatal(err)
+	}
+	if body := rec.bodies[0]; body["FilterExpression"] != *expr.Filter() || body["KeyConditionExpression"] != *expr.KeyCondition() {
+		t.Fatalf("unexpected request: %v", body)
+	}
+
+	// filter only, for scan
+	if expr, err = dynamox.Attr("total").Cond(dynamox.Between, 1, 10).Build(); err != nil || expr.Filter() == nil {
+		t.Fatal("expected filter", err)
+	}
+	if !dynamox.And().IsEmpty() || !dynamox.Not(dynamox.Filter{}).IsEmpty() {
+		t.Fatal("expected empty filter")
+	}
+	if _, err = dynamox.And(filter, dynamox.Attr("sk").Cond(dynamox.BeginsWith, 1)).Build(); !errors.Is(err, dynamox.ErrBeginsWithPrefixType) {
+		t.Fatalf("expected ErrBeginsWithPrefixType, got %v", err)
+	}
+	if _, err = dynamox.NewKeyCondBuilder().WithPK("customerId", "123").WithFilter(dynamox.Attr("status").In()).Build(); !errors.Is(err, dynamox.ErrRequiredKeyAndValue) {
+		t.Fatalf("expected ErrRequiredKeyAndValue, got %v", err)
+	}
+	values := make([]any, dynamox.InOperandsLimit+1)
+	for i := range values {
+		values[i] = i
+	}
+	if _, err = dynamox.Attr("n").In(values[:dynamox.InOperandsLimit]...).Build(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = dynamox.Attr("n").In(values...).Build(); !errors.Is(err, dynamox.ErrTooManyInOperands) {
+		t.Fatalf("expected ErrTooManyInOperands, got %v", err)
+	}
+}
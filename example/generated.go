@@ -0,0 +1,18 @@
+package example
+
+import "github.com/go-chujang/dynamox"
+
+//go:generate go run ../cmd/dynamox-gen
+
+// genProfile is profile without hand-written KeyBase methods, see generated_dynamox.go
+//
+//dynamox:table CustomerBookmark
+type genProfile struct {
+	CustomerId string `dynamodbav:"customerId" dynamox:"pk"`
+	Sk         string `dynamodbav:"sk" dynamox:"sk,prefix=CUST,from=CustomerId"`
+	Email      string `dynamodbav:"email"`
+	Fullname   string `dynamodbav:"fullName,omitempty"`
+	Visits     int    `dynamodbav:"visits,omitempty"`
+	Avatar     []byte `dynamodbav:"avatar,omitempty"`
+	dynamox.Model
+}
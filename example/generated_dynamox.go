@@ -0,0 +1,153 @@
+// Code generated by dynamox-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-chujang/dynamox"
+)
+
+const (
+	genProfileAttrCustomerId = "customerId"
+	genProfileAttrSk         = "sk"
+	genProfileAttrEmail      = "email"
+	genProfileAttrFullname   = "fullName"
+	genProfileAttrVisits     = "visits"
+	genProfileAttrAvatar     = "avatar"
+)
+
+var (
+	_ dynamox.KeyedItem          = (*genProfile)(nil)
+	_ attributevalue.Marshaler   = (*genProfile)(nil)
+	_ attributevalue.Unmarshaler = (*genProfile)(nil)
+)
+
+func (genProfile) Table() string   { return "CustomerBookmark" }
+func (genProfile) PKField() string { return genProfileAttrCustomerId }
+func (genProfile) SKField() string { return genProfileAttrSk }
+func (v genProfile) PK() any       { return v.CustomerId }
+func (v genProfile) SK() any       { return v.Sk }
+
+func (v *genProfile) GetKeyBase() dynamox.KeyBase { return dynamoxKeyGenProfile{v} }
+
+func (v *genProfile) SaveSK() error { return v.SaveSKWith(dynamox.GlobalConventions()) }
+
+func (v *genProfile) SaveSKWith(cv dynamox.Conventions) error {
+	if v.Sk == "" {
+		srcs := []string{v.CustomerId}
+		for _, src := range srcs {
+			if src == "" {
+				return fmt.Errorf("%w: %s", dynamox.ErrInsufficientCompositeKeySrc, genProfileAttrSk)
+			}
+		}
+		v.Sk = cv.Composite("CUST", srcs...)
+	}
+	return nil
+}
+
+func (v genProfile) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	m := make(map[string]types.AttributeValue, 6)
+	if av, err := attributevalue.Marshal(v.Model); err != nil {
+		return nil, err
+	} else if em, ok := av.(*types.AttributeValueMemberM); ok {
+		maps.Copy(m, em.Value) // flattened, the fields of genProfile below win
+	}
+	m[genProfileAttrCustomerId] = &types.AttributeValueMemberS{Value: v.CustomerId}
+	m[genProfileAttrSk] = &types.AttributeValueMemberS{Value: v.Sk}
+	m[genProfileAttrEmail] = &types.AttributeValueMemberS{Value: v.Email}
+	if v.Fullname != "" {
+		m[genProfileAttrFullname] = &types.AttributeValueMemberS{Value: v.Fullname}
+	}
+	if v.Visits != 0 {
+		m[genProfileAttrVisits] = &types.AttributeValueMemberN{Value: strconv.FormatInt(int64(v.Visits), 10)}
+	}
+	if v.Avatar != nil {
+		m[genProfileAttrAvatar] = &types.AttributeValueMemberB{Value: v.Avatar}
+	}
+	return &types.AttributeValueMemberM{Value: m}, nil
+}
+
+func (v *genProfile) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	m, ok := av.(*types.AttributeValueMemberM)
+	if !ok {
+		return dynamox.ErrExpectedMapAttribute
+	}
+	if err := attributevalue.Unmarshal(m, &v.Model); err != nil {
+		return err
+	}
+	switch av := m.Value[genProfileAttrCustomerId].(type) {
+	case nil:
+	case *types.AttributeValueMemberNULL:
+		v.CustomerId = ""
+	case *types.AttributeValueMemberS:
+		v.CustomerId = av.Value
+	default:
+		return fmt.Errorf("%w: %s", dynamox.ErrExpectedStringAttribute, genProfileAttrCustomerId)
+	}
+	switch av := m.Value[genProfileAttrSk].(type) {
+	case nil:
+	case *types.AttributeValueMemberNULL:
+		v.Sk = ""
+	case *types.AttributeValueMemberS:
+		v.Sk = av.Value
+	default:
+		return fmt.Errorf("%w: %s", dynamox.ErrExpectedStringAttribute, genProfileAttrSk)
+	}
+	switch av := m.Value[genProfileAttrEmail].(type) {
+	case nil:
+	case *types.AttributeValueMemberNULL:
+		v.Email = ""
+	case *types.AttributeValueMemberS:
+		v.Email = av.Value
+	default:
+		return fmt.Errorf("%w: %s", dynamox.ErrExpectedStringAttribute, genProfileAttrEmail)
+	}
+	switch av := m.Value[genProfileAttrFullname].(type) {
+	case nil:
+	case *types.AttributeValueMemberNULL:
+		v.Fullname = ""
+	case *types.AttributeValueMemberS:
+		v.Fullname = av.Value
+	default:
+		return fmt.Errorf("%w: %s", dynamox.ErrExpectedStringAttribute, genProfileAttrFullname)
+	}
+	switch av := m.Value[genProfileAttrVisits].(type) {
+	case nil:
+	case *types.AttributeValueMemberNULL:
+		v.Visits = 0
+	case *types.AttributeValueMemberN:
+		n, err := strconv.ParseInt(av.Value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %w", genProfileAttrVisits, err)
+		}
+		v.Visits = int(n)
+	default:
+		return fmt.Errorf("%w: %s", dynamox.ErrExpectedNumberAttribute, genProfileAttrVisits)
+	}
+	switch av := m.Value[genProfileAttrAvatar].(type) {
+	case nil:
+	case *types.AttributeValueMemberNULL:
+		v.Avatar = nil
+	case *types.AttributeValueMemberB:
+		v.Avatar = av.Value
+	default:
+		return fmt.Errorf("%w: %s %T", dynamox.ErrUnexpectedAttributeType, genProfileAttrAvatar, av)
+	}
+	return nil
+}
+
+// dynamoxKeyGenProfile marshals only the key attributes of genProfile, for MarshalMapOnlyKey
+type dynamoxKeyGenProfile struct{ *genProfile }
+
+func (k dynamoxKeyGenProfile) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	v := k.genProfile
+	m := make(map[string]types.AttributeValue, 2)
+	m[genProfileAttrCustomerId] = &types.AttributeValueMemberS{Value: v.CustomerId}
+	m[genProfileAttrSk] = &types.AttributeValueMemberS{Value: v.Sk}
+	return &types.AttributeValueMemberM{Value: m}, nil
+}
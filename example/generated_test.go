@@ -0,0 +1,86 @@
+package example
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-chujang/dynamox"
+)
+
+func Test_generated(t *testing.T) {
+	model := dynamox.Model{CreatedAtOnly: dynamox.CreatedAtOnly{CreatedAt: 1714564800000}}
+	item := &genProfile{CustomerId: "123", Email: "shirley@example.net", Visits: 7, Model: model}
+	m, err := dynamox.MarshalMap(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Sk != profileSortKeyPrefix.Composite("123") || item.SKField() != genProfileAttrSk {
+		t.Fatalf("unexpected sk: %s", item.Sk)
+	}
+	if _, omitted := m[genProfileAttrFullname]; omitted || len(m) != 6 {
+		t.Fatalf("unexpected attributes: %v", m)
+	}
+	if _, flattened := m["deletedAt"]; !flattened {
+		t.Fatalf("expected the embedded Model flattened: %v", m)
+	}
+
+	// same attributes as attributevalue: empty strings as S "", nil []byte omitted, empty kept
+	type plainProfile genProfile
+	for _, v := range []*genProfile{
+		{CustomerId: "123", Sk: "CUST#123", Model: model},
+		{CustomerId: "123", Sk: "CUST#123", Avatar: []byte{}, Model: dynamox.Model{CreatedAtOnly: model.CreatedAtOnly, DeletedAt: 1}},
+	} {
+		want, err := attributevalue.MarshalMap(plainProfile(*v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := dynamox.MarshalMap(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("unexpected attributes:\n%v\nattributevalue:\n%v", got, want)
+		}
+	}
+
+	key, err := dynamox.MarshalMapOnlyKey(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != 2 {
+		t.Fatalf("unexpected key: %v", key)
+	}
+
+	var out genProfile
+	if err = dynamox.UnmarshalMap(m, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, *item) {
+		t.Fatalf("unexpected roundtrip: %+v", out)
+	}
+
+	// NULL zeroes a field and an absent attribute leaves it, as attributevalue does
+	nulls := map[string]types.AttributeValue{
+		genProfileAttrEmail:  &types.AttributeValueMemberNULL{Value: true},
+		genProfileAttrVisits: &types.AttributeValueMemberNULL{Value: true},
+		genProfileAttrAvatar: &types.AttributeValueMemberNULL{Value: true},
+	}
+	got, want := *item, plainProfile(*item)
+	got.Avatar, want.Avatar = []byte("png"), []byte("png")
+	if err = dynamox.UnmarshalMap(nulls, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err = attributevalue.UnmarshalMap(nulls, &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(plainProfile(got), want) || got.Email != "" || got.Visits != 0 || got.Avatar != nil || got.CustomerId != "123" {
+		t.Fatalf("unexpected NULL unmarshal: %+v, attributevalue: %+v", got, want)
+	}
+
+	if _, err = dynamox.MarshalMap(&genProfile{}); !errors.Is(err, dynamox.ErrInsufficientCompositeKeySrc) {
+		t.Fatalf("expected ErrInsufficientCompositeKeySrc, got %v", err)
+	}
+}
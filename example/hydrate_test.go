@@ -0,0 +1,111 @@
+package example
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-chujang/dynamox"
+)
+
+func openOrderItem(orderId string, full bool) map[string]types.AttributeValue {
+	m := map[string]types.AttributeValue{
+		"pk":         &types.AttributeValueMemberS{Value: "ORDER#" + orderId},
+		"sk":         &types.AttributeValueMemberS{Value: "ORDER"},
+		"openUserId": &types.AttributeValueMemberS{Value: "u1"},
+		"openedAt":   &types.AttributeValueMemberS{Value: "2024-05-01"},
+	}
+	if full {
+		m["userId"] = &types.AttributeValueMemberS{Value: "u1"}
+		m["orderId"] = &types.AttributeValueMemberS{Value: orderId}
+		m["status"] = &types.AttributeValueMemberS{Value: "OPEN"}
+	}
+	return m
+}
+
+// batchGetResponse answers responses of table, leaving unprocessed for a retry
+func batchGetResponse(t *testing.T, table string, responses, unprocessed []map[string]types.AttributeValue) string {
+	t.Helper()
+	var items, keys struct{ Items []any }
+	if err := json.Unmarshal([]byte(queryResponse(t, responses)), &items); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(queryResponse(t, unprocessed)), &keys); err != nil {
+		t.Fatal(err)
+	}
+	out := map[string]any{"Responses": map[string]any{table: items.Items}}
+	if len(keys.Items) > 0 {
+		out["UnprocessedKeys"] = map[string]any{table: map[string]any{"Keys": keys.Items}}
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func Test_hydrate(t *testing.T) {
+	rec := &recorder{responses: []string{
+		// index order o2, o1, o3 of the KEYS_ONLY openOrders
+		queryResponse(t, []map[string]types.AttributeValue{openOrderItem("o2", false), openOrderItem("o1", false), openOrderItem("o3", false)}),
+		// o2 was deleted since, o1 unprocessed on the first attempt
+		batchGetResponse(t, "Shop", []map[string]types.AttributeValue{openOrderItem("o3", true)}, []map[string]types.AttributeValue{openOrderItem("o1", false)}),
+		batchGetResponse(t, "Shop", []map[string]types.AttributeValue{openOrderItem("o1", true)}, nil),
+	}}
+	c := newRecordedClient(rec)
+
+	var orders []shopOrder
+	query := dynamox.NewCtxQuery(t.Context()).SetTable("Shop").
+		SetKeyCondBuilder(dynamox.NewKeyCondBuilder().WithIndex(openOrders, "u1")).
+		SetProjectionOf(&orders).
+		SetHydrate(true)
+	count, _, err := c.Query(query, &orders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.bodies) != 3 {
+		t.Fatalf("expected query and 2 BatchGet, got %d requests", len(rec.bodies))
+	}
+	if _, ok := rec.bodies[0]["ProjectionExpression"]; ok {
+		t.Fatalf("unexpected projection on the index: %v", rec.bodies[0]["ProjectionExpression"])
+	}
+	request := rec.bodies[1]["RequestItems"].(map[string]any)["Shop"].(map[string]any)
+	if keys := request["Keys"].([]any); len(keys) != 3 || request["ProjectionExpression"] == nil {
+		t.Fatalf("unexpected BatchGet request: %v", request)
+	}
+	ids := make([]string, 0, len(orders))
+	for _, o := range orders {
+		if o.Status != "OPEN" {
+			t.Fatalf("expected full item: %+v", o)
+		}
+		ids = append(ids, o.OrderId)
+	}
+	if count != 2 || !slices.Equal(ids, []string{"o1", "o3"}) {
+		t.Fatalf("expected index order o1, o3: %d %v", count, ids)
+	}
+
+	// an ALL index is returned as is
+	rec = &recorder{responses: []string{queryResponse(t, []map[string]types.AttributeValue{openOrderItem("o1", true)})}}
+	c = newRecordedClient(rec)
+	orders = nil
+	query = dynamox.NewCtxQuery(t.Context()).SetTable("Shop").
+		SetKeyCondBuilder(dynamox.NewKeyCondBuilder().WithIndex(openOrders.WithProjectionAll(), "u1")).
+		SetHydrate(true)
+	if _, _, err = c.Query(query, &orders); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.bodies) != 1 || len(orders) != 1 {
+		t.Fatalf("unexpected hydration of an ALL index: %d requests", len(rec.bodies))
+	}
+
+	// the table keys must be derivable from the output
+	query = dynamox.NewCtxQuery(t.Context()).SetTable("Shop").
+		SetKeyCondBuilder(dynamox.NewKeyCondBuilder().WithIndex(openOrders, "u1")).
+		SetHydrate(true)
+	rec.responses = []string{queryResponse(t, []map[string]types.AttributeValue{openOrderItem("o1", false)})}
+	if _, _, err = c.Query(query, &[]map[string]any{}); !errors.Is(err, dynamox.ErrUnderivableTableKey) {
+		t.Fatalf("expected ErrUnderivableTableKey, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package example
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-chujang/dynamox"
+	"github.com/google/uuid"
+)
+
+type receipt struct {
+	base
+	ReceiptId dynamox.ID    `dynamodbav:"receiptId"`
+	TraceId   dynamox.ID    `dynamodbav:"traceId" dynamoxid:"uuidv7"`
+	Seq       dynamox.IDSeq `dynamodbav:"seq" dynamoxid:"ksuid"`
+}
+
+func (r *receipt) GetKeyBase() dynamox.KeyBase { return &r.base }
+func (r *receipt) SaveSK() error {
+	if r.Sk == "" {
+		r.Sk = dynamox.SortKeyPrefix("RECEIPT").Composite(r.ReceiptId.String())
+	}
+	return nil
+}
+
+func Test_idGenerator(t *testing.T) {
+	a, b := dynamox.NewSeededIDGenerator(42), dynamox.NewSeededIDGenerator(42)
+	for range 3 {
+		x, y := a.NewID(), b.NewID()
+		if x != y {
+			t.Fatal("seeded generator is not deterministic")
+		}
+		if u, err := uuid.Parse(x); err != nil || u.Version() != 4 {
+			t.Fatalf("unexpected seeded id: %s", x)
+		}
+	}
+	if u, err := uuid.Parse(dynamox.UUIDv7Generator.NewID()); err != nil || u.Version() != 7 {
+		t.Fatal("unexpected UUIDv7")
+	}
+
+	clock := dynamox.NewFakeClock(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+	dynamox.SetClock(clock)
+	defer dynamox.SetClock(nil)
+	k1 := dynamox.KSUIDGenerator.NewID()
+	clock.Advance(time.Second)
+	k2 := dynamox.KSUIDGenerator.NewID()
+	if len(k1) != 27 || k1 >= k2 {
+		t.Fatalf("unexpected KSUIDs: %s, %s", k1, k2)
+	}
+
+	// per type, written back to the struct
+	dynamox.SetIDGenerator(dynamox.NewSeededIDGenerator(7))
+	defer dynamox.SetIDGenerator(nil)
+	seeded := dynamox.NewSeededIDGenerator(7) // the same sequence as the global one
+	expected := seeded.NewID()
+	item := &receipt{base: base{CustomerId: "123"}}
+	m, err := dynamox.MarshalMap(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.ReceiptId.String() != expected || item.Sk != dynamox.SortKeyPrefix("RECEIPT").Composite(expected) {
+		t.Fatalf("unexpected receiptId: %s", item.ReceiptId)
+	}
+	if s, _ := m["receiptId"].(*types.AttributeValueMemberS); s == nil || s.Value != expected {
+		t.Fatal("stored id differs from the struct")
+	}
+	// per field
+	if u, err := uuid.Parse(item.TraceId.String()); err != nil || u.Version() != 7 {
+		t.Fatalf("unexpected traceId: %s", item.TraceId)
+	}
+	if len(item.Seq) != 27 {
+		t.Fatalf("unexpected seq: %s", item.Seq)
+	}
+
+	// zero values marshaled on their own use the global generator too
+	av, err := attributevalue.Marshal(dynamox.ID(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := av.(*types.AttributeValueMemberS); s == nil || s.Value != seeded.NewID() {
+		t.Fatalf("unexpected id: %v", av)
+	}
+
+	type unknown struct {
+		receipt
+		Other dynamox.ID `dynamodbav:"other" dynamoxid:"snowflake"`
+	}
+	if _, err = dynamox.MarshalMap(&unknown{receipt: receipt{base: base{CustomerId: "123"}}}); !errors.Is(err, dynamox.ErrUnknownIDGenerator) {
+		t.Fatalf("expected ErrUnknownIDGenerator, got %v", err)
+	}
+}
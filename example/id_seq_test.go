@@ -0,0 +1,107 @@
+package example
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chujang/dynamox"
+)
+
+func Test_idSeq(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		ids []dynamox.IDSeq
+	)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			local := make([]dynamox.IDSeq, 0, 1000)
+			for range 1000 {
+				local = append(local, dynamox.NewIDSeq())
+			}
+			if !slices.IsSorted(local) {
+				t.Error("IDSeq not monotonic in a goroutine")
+			}
+			mu.Lock()
+			ids = append(ids, local...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	slices.Sort(ids)
+	if len(slices.Compact(ids)) != 8000 {
+		t.Fatal("duplicated IDSeq")
+	}
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	id, err := dynamox.NewIDSeqAt(at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts, err := id.Time(); err != nil || !ts.Equal(at) {
+		t.Fatalf("unexpected timestamp: %v, %v", ts, err)
+	}
+	lower, upper, err := dynamox.IDSeqRange(at.Add(-time.Minute), at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !(lower <= id && id <= upper) {
+		t.Fatal("IDSeq out of range")
+	}
+	if before, _ := dynamox.NewIDSeqAt(at.Add(-2 * time.Minute)); before >= lower {
+		t.Fatal("IDSeq before range")
+	}
+	// times ulid can't encode are rejected, not wrapped
+	for _, out := range []time.Time{{}, time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC)} {
+		if _, err = dynamox.NewIDSeqAt(out); !errors.Is(err, dynamox.ErrIDSeqTimeOutOfRange) {
+			t.Fatalf("%v: expected ErrIDSeqTimeOutOfRange, got %v", out, err)
+		}
+		if _, _, err = dynamox.IDSeqRange(out, at); !errors.Is(err, dynamox.ErrIDSeqTimeOutOfRange) {
+			t.Fatalf("%v: expected ErrIDSeqTimeOutOfRange, got %v", out, err)
+		}
+	}
+	if _, err = dynamox.IDSeqUpperBound(time.Unix(0, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dynamox.NewKeyCondBuilder().WithPK("customerId", "123").WithSK(dynamox.Between, "sk", lower, upper).Build(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dynamox.IDSeq("not-ulid").Time(); err == nil {
+		t.Fatal("expected parse error")
+	}
+
+	// entropy exhausted within at's millisecond fails instead of moving to the next one
+	src := dynamox.NewIDSeqSource(&maxEntropy{})
+	if id, err = src.At(at); err != nil {
+		t.Fatal(err)
+	}
+	if ts, _ := id.Time(); !ts.Equal(at) {
+		t.Fatalf("unexpected timestamp: %v", ts)
+	}
+	if _, err = src.At(at); !errors.Is(err, dynamox.ErrIDSeqOverflow) {
+		t.Fatalf("expected ErrIDSeqOverflow, got %v", err)
+	}
+	if next, err := src.At(at.Add(time.Millisecond)); err != nil || next <= id {
+		t.Fatalf("expected the next millisecond to succeed, got %s, %v", next, err)
+	}
+}
+
+// maxEntropy starts with 10 bytes of ones, the largest entropy, for the first IDSeq of a millisecond,
+// so the increment of the next one overflows
+type maxEntropy struct{ n int }
+
+func (e *maxEntropy) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0x01 // increments, below ulid's bound
+		if e.n < 10 {
+			p[i] = 0xff
+		}
+		e.n++
+	}
+	return len(p), nil
+}
@@ -0,0 +1,244 @@
+package example
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-chujang/dynamox"
+	"github.com/go-chujang/dynamox/dynsa"
+)
+
+func Test_indexQuery(t *testing.T) {
+	var (
+		byUrl    = dynamox.MustGSI(dynsa.AttrDefS("url").Aws(), dynsa.AttrDefS("customerId").Aws())
+		byScore  = dynamox.MustGSI(dynsa.AttrDefS("customerId").Aws(), dynsa.AttrDefN("score").Aws())
+		byFolder = dynamox.MustLSI(dynsa.AttrDefS("customerId").Aws(), dynsa.AttrDefS("folder").Aws())
+		rec      = &recorder{}
+		c        = newRecordedClient(rec)
+	)
+
+	kcb := dynamox.NewKeyCondBuilder().WithIndex(byUrl, "https://aws.amazon.com").WithIndexSK(dynamox.BeginsWith, "12")
+	query := dynamox.NewCtxQuery(t.Context()).SetTable("CustomerBookmark").SetKeyCondBuilder(kcb)
+	if _, _, err := c.Query(query, &[]bookmark{}); err != nil {
+		t.Fatal(err)
+	}
+	body := rec.bodies[0]
+	if body["IndexName"] != byUrl.Name() {
+		t.Fatalf("unexpected IndexName: %v", body["IndexName"])
+	}
+	names := map[string]bool{}
+	for _, name := range body["ExpressionAttributeNames"].(map[string]any) {
+		names[name.(string)] = true
+	}
+	if !names["url"] || !names["customerId"] {
+		t.Fatalf("unexpected names: %v", names)
+	}
+
+	// consistent read is fine on LSI, rejected on GSI before sending
+	lsi := dynamox.NewKeyCondBuilder().WithIndex(byFolder, "123").WithIndexSK(dynamox.Equal, "news")
+	if _, _, err := c.Query(dynamox.NewCtxQuery(t.Context()).SetTable("CustomerBookmark").SetConsistentRead(true).SetKeyCondBuilder(lsi), &[]bookmark{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Query(query.SetConsistentRead(true), &[]bookmark{}); !errors.Is(err, dynamox.ErrConsistentReadOnGSI) {
+		t.Fatalf("expected ErrConsistentReadOnGSI, got %v", err)
+	}
+	if len(rec.bodies) != 2 {
+		t.Fatalf("unexpected requests: %d", len(rec.bodies))
+	}
+
+	for _, tc := range []struct {
+		kcb *dynamox.KeyCondBuilder
+		err error
+	}{
+		{dynamox.NewKeyCondBuilder().WithIndex(byScore, "123").WithIndexSK(dynamox.Between, 10, 20), nil},
+		{dynamox.NewKeyCondBuilder().WithIndex(byScore, "123").WithIndexSK(dynamox.BeginsWith, "1"), dynamox.ErrUnsupportedKeyCondition},
+		{dynamox.NewKeyCondBuilder().WithIndex(byScore, "123").WithIndexSK(dynamox.GreaterThan, "10"), dynamox.ErrUnexpectedAttributeType},
+		{dynamox.NewKeyCondBuilder().WithIndex(byScore, "123").WithIndexSK(dynamox.Between, 10, "20"), dynamox.ErrUnexpectedAttributeType},
+		{dynamox.NewKeyCondBuilder().WithIndex(byScore, 123), dynamox.ErrUnexpectedAttributeType},
+		{dynamox.NewKeyCondBuilder().WithIndex(byScore, "123").WithSK(dynamox.Equal, "sk", "x"), dynamox.ErrUnexpectedSortKey},
+		{dynamox.NewKeyCondBuilder().WithIndex(byScore, "123").WithPK("customerId", "321"), nil},
+		{dynamox.NewKeyCondBuilder().WithIndex(byScore, "123").WithPK("url", "x"), dynamox.ErrUnexpectedPartitionKey},
+		{dynamox.NewKeyCondBuilder().WithIndex(dynamox.MustGSI(dynsa.AttrDefS("email").Aws()), "a@b.c").WithIndexSK(dynamox.Equal, "x"), dynamox.ErrUnexpectedSortKey},
+	} {
+		if _, err := tc.kcb.Build(); !errors.Is(err, tc.err) {
+			t.Fatalf("expected %v, got %v", tc.err, err)
+		}
+	}
+
+	if idx, err := dynamox.NewByName(byUrl.Name(), types.ScalarAttributeTypeS); err != nil || idx.Kind() != dynamox.GSI {
+		t.Fatal("unexpected index kind", err)
+	}
+}
+
+func Test_indexDefinition(t *testing.T) {
+	var (
+		byEmail = dynamox.MustGSI(dynsa.AttrDefS("email").Aws()).WithKeysOnly().WithThroughput(5, 5)
+		byUrl   = dynamox.MustGSI(dynsa.AttrDefS("url").Aws(), dynsa.AttrDefS("customerId").Aws()).WithInclude("title", "folder")
+		byTitle = dynamox.MustLSI(dynsa.AttrDefS("customerId").Aws(), dynsa.AttrDefS("title").Aws())
+	)
+	gsis, lsis, err := dynamox.SecondaryIndexes(byEmail, byUrl, byTitle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gsis) != 2 || len(lsis) != 1 {
+		t.Fatalf("unexpected indexes: %d, %d", len(gsis), len(lsis))
+	}
+	if gsis[0].Projection.ProjectionType != types.ProjectionTypeKeysOnly || *gsis[0].ProvisionedThroughput.ReadCapacityUnits != 5 {
+		t.Fatal("unexpected byEmail")
+	}
+	if p := gsis[1].Projection; p.ProjectionType != types.ProjectionTypeInclude || len(p.NonKeyAttributes) != 2 {
+		t.Fatal("unexpected byUrl")
+	}
+	if lsis[0].Projection.ProjectionType != types.ProjectionTypeAll || *lsis[0].IndexName != byTitle.Name() {
+		t.Fatal("unexpected byTitle")
+	}
+
+	defs, err := dynamox.AttributeDefinitions([]types.AttributeDefinition{dynsa.AttrDefS("customerId").Aws(), dynsa.AttrDefS("sk").Aws()}, byEmail, byUrl, byTitle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(defs) != 5 { // customerId, sk, email, url, title
+		t.Fatalf("unexpected attribute definitions: %d", len(defs))
+	}
+	if _, err = dynamox.AttributeDefinitions([]types.AttributeDefinition{dynsa.AttrDefN("customerId").Aws()}, byUrl); !errors.Is(err, dynamox.ErrInvalidAttributeDefinition) {
+		t.Fatalf("expected ErrInvalidAttributeDefinition, got %v", err)
+	}
+
+	// the hand-written definitions of the CustomerBookmark table in keyeditem_test.go
+	var (
+		gsiByEmail          = dynamox.MustGSI(dynsa.AttrDefS("email").Aws())
+		gsiByUrl            = dynamox.MustGSI(dynsa.AttrDefS("url").Aws(), dynsa.AttrDefS("customerId").Aws())
+		gsiByCustomerFolder = dynamox.MustGSI(dynsa.AttrDefS("customerId").Aws(), dynsa.AttrDefS("folder").Aws())
+		tableKeys           = []types.AttributeDefinition{dynsa.AttrDefS("customerId").Aws(), dynsa.AttrDefS("sk").Aws()}
+	)
+	if gsis, _, err = dynamox.SecondaryIndexes(gsiByEmail, gsiByUrl, gsiByCustomerFolder); err != nil {
+		t.Fatal(err)
+	}
+	var handWritten []types.GlobalSecondaryIndex
+	for _, idx := range []dynamox.Index{gsiByEmail, gsiByUrl, gsiByCustomerFolder} {
+		handWritten = append(handWritten, types.GlobalSecondaryIndex{
+			IndexName: aws.String(idx.Name()), KeySchema: idx.KeySchema(), Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+		})
+	}
+	if !reflect.DeepEqual(gsis, handWritten) {
+		t.Fatalf("unexpected GSIs: %+v", gsis)
+	}
+	if defs, err = dynamox.AttributeDefinitions(tableKeys, gsiByEmail, gsiByUrl, gsiByCustomerFolder); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(defs, append(tableKeys, dynsa.AttrDefS("email").Aws(), dynsa.AttrDefS("url").Aws(), dynsa.AttrDefS("folder").Aws())) {
+		t.Fatalf("unexpected attribute definitions: %+v", defs)
+	}
+
+	many := make([]string, 60)
+	for i := range many {
+		many[i] = "attr" + string(rune('A'+i))
+	}
+	for _, tc := range []struct {
+		indexes []dynamox.Index
+		err     error
+	}{
+		{[]dynamox.Index{byUrl.WithInclude()}, dynamox.ErrInvalidIndexProjection},
+		{[]dynamox.Index{byUrl.WithInclude("title", "title")}, dynamox.ErrInvalidIndexProjection},
+		{[]dynamox.Index{byUrl.WithInclude("url")}, dynamox.ErrInvalidIndexProjection},
+		{[]dynamox.Index{byUrl.WithInclude(many...), byEmail.WithInclude(many...)}, dynamox.ErrInvalidIndexProjection},
+		{[]dynamox.Index{byTitle.WithThroughput(1, 1)}, dynamox.ErrInvalidIndexThroughput},
+		{[]dynamox.Index{byEmail.WithThroughput(0, 1)}, dynamox.ErrInvalidIndexThroughput},
+		{[]dynamox.Index{byUrl.WithInclude(many...).WithProjectionAll()}, nil},
+	} {
+		if _, _, err = dynamox.SecondaryIndexes(tc.indexes...); !errors.Is(err, tc.err) {
+			t.Fatalf("expected %v, got %v", tc.err, err)
+		}
+	}
+	if _, err = byTitle.GlobalSecondaryIndex(); !errors.Is(err, dynamox.ErrUnexpectedIndexKind) {
+		t.Fatalf("expected ErrUnexpectedIndexKind, got %v", err)
+	}
+}
+
+func Test_indexNamer(t *testing.T) {
+	// the default namer keeps fields as is
+	byUserId := dynamox.MustGSI(dynsa.AttrDefS("user-id").Aws())
+	if byUserId.Name() != "gsi-user-id" {
+		t.Fatalf("unexpected name: %s", byUserId.Name())
+	}
+	if parsed, _ := dynamox.NewByName(byUserId.Name(), types.ScalarAttributeTypeS); parsed.PKField() != "user" || parsed.SKField() != "id" {
+		t.Fatalf("unexpected index: %s, %s", parsed.PKField(), parsed.SKField())
+	}
+	for _, name := range []string{"GSI1", "gsi", "gsi-a-b-c", "idx-a"} {
+		if _, err := dynamox.NewByName(name, types.ScalarAttributeTypeS); !errors.Is(err, dynamox.ErrUnexpectedIndexFormat) {
+			t.Fatalf("%s: expected ErrUnexpectedIndexFormat, got %v", name, err)
+		}
+	}
+	if _, err := dynamox.NewGSI(dynsa.AttrDefS("user id").Aws()); !errors.Is(err, dynamox.ErrUnexpectedIndexFormat) {
+		t.Fatalf("expected ErrUnexpectedIndexFormat, got %v", err)
+	}
+
+	// escaping is opt-in, fields containing the separator round-trip
+	dynamox.SetIndexNamer(dynamox.EscapedIndexNamer('-'))
+	defer dynamox.SetIndexNamer(nil)
+	byUserId = dynamox.MustGSI(dynsa.AttrDefS("user-id").Aws(), dynsa.AttrDefN("created.at").Aws())
+	if byUserId.Name() != "gsi-user.-id-created..at" {
+		t.Fatalf("unexpected name: %s", byUserId.Name())
+	}
+	parsed, err := dynamox.NewByName(byUserId.Name(), types.ScalarAttributeTypeS, types.ScalarAttributeTypeN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Kind() != dynamox.GSI || parsed.PKField() != "user-id" || parsed.SKField() != "created.at" {
+		t.Fatalf("unexpected index: %s, %s", parsed.PKField(), parsed.SKField())
+	}
+	if _, err = dynamox.NewByName("gsi-a.", types.ScalarAttributeTypeS); !errors.Is(err, dynamox.ErrUnexpectedIndexFormat) {
+		t.Fatalf("expected ErrUnexpectedIndexFormat, got %v", err)
+	}
+
+	dynamox.SetIndexNamer(dynamox.EscapedIndexNamer('_'))
+	if idx := dynamox.MustLSI(dynsa.AttrDefS("customerId").Aws(), dynsa.AttrDefS("created_at").Aws()); idx.Name() != "lsi_customerId_created._at" {
+		t.Fatalf("unexpected name: %s", idx.Name())
+	}
+	dynamox.SetIndexNamer(dynamox.SepIndexNamer('_'))
+	if idx := dynamox.MustLSI(dynsa.AttrDefS("customerId").Aws(), dynsa.AttrDefS("createdAt").Aws()); idx.Name() != "lsi_customerId_createdAt" {
+		t.Fatalf("unexpected name: %s", idx.Name())
+	}
+
+	// conventional names of single-table designs
+	indexes, err := dynamox.IndexesOf(&types.TableDescription{
+		AttributeDefinitions: []types.AttributeDefinition{
+			dynsa.AttrDefS("pk").Aws(), dynsa.AttrDefS("sk").Aws(),
+			dynsa.AttrDefS("GSI1PK").Aws(), dynsa.AttrDefN("GSI1SK").Aws(), dynsa.AttrDefS("LSI1SK").Aws(),
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndexDescription{{
+			IndexName: aws.String("GSI1"),
+			KeySchema: []types.KeySchemaElement{
+				{AttributeName: aws.String("GSI1PK"), KeyType: types.KeyTypeHash},
+				{AttributeName: aws.String("GSI1SK"), KeyType: types.KeyTypeRange},
+			},
+			Projection:            &types.Projection{ProjectionType: types.ProjectionTypeInclude, NonKeyAttributes: []string{"title"}},
+			ProvisionedThroughput: &types.ProvisionedThroughputDescription{ReadCapacityUnits: aws.Int64(0), WriteCapacityUnits: aws.Int64(0)},
+		}},
+		LocalSecondaryIndexes: []types.LocalSecondaryIndexDescription{{
+			IndexName: aws.String("LSI1"),
+			KeySchema: []types.KeySchemaElement{
+				{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+				{AttributeName: aws.String("LSI1SK"), KeyType: types.KeyTypeRange},
+			},
+			Projection: &types.Projection{ProjectionType: types.ProjectionTypeKeysOnly},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gsi1, lsi1 := indexes[0], indexes[1]
+	if gsi1.Name() != "GSI1" || gsi1.Kind() != dynamox.GSI || gsi1.SKDef().AttributeType != types.ScalarAttributeTypeN ||
+		gsi1.ProjectionType() != types.ProjectionTypeInclude || gsi1.Throughput() != nil {
+		t.Fatal("unexpected GSI1")
+	}
+	if lsi1.Name() != "LSI1" || lsi1.Kind() != dynamox.LSI || lsi1.ProjectionType() != types.ProjectionTypeKeysOnly {
+		t.Fatal("unexpected LSI1")
+	}
+	if _, err = dynamox.NewKeyCondBuilder().WithIndex(gsi1, "USER#1").WithIndexSK(dynamox.GreaterThan, 10).Build(); err != nil {
+		t.Fatal(err)
+	}
+}
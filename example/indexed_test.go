@@ -0,0 +1,173 @@
+package example
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-chujang/dynamox"
+	"github.com/go-chujang/dynamox/dynsa"
+)
+
+// single-table entities sharing the overloaded GSI1
+var gsi1 = func() dynamox.Index {
+	idx, err := dynamox.NewIndexNamed("GSI1", dynamox.GSI, dynsa.AttrDefS("GSI1PK").Aws(), dynsa.AttrDefS("GSI1SK").Aws())
+	if err != nil {
+		panic(err)
+	}
+	return idx
+}()
+
+func init() { dynamox.RegisterIndexes("App", gsi1) }
+
+type appKey struct {
+	Pk string `dynamodbav:"pk"`
+	Sk string `dynamodbav:"sk"`
+}
+
+func (appKey) Table() string   { return "App" }
+func (appKey) PKField() string { return "pk" }
+func (appKey) SKField() string { return "sk" }
+func (k appKey) PK() any       { return k.Pk }
+func (k appKey) SK() any       { return k.Sk }
+
+type appUser struct {
+	appKey
+	UserId string `dynamodbav:"userId"`
+	Email  string `dynamodbav:"email"`
+}
+
+func (u *appUser) GetKeyBase() dynamox.KeyBase { return &u.appKey }
+func (u *appUser) SaveSK() error {
+	u.Pk, u.Sk = "USER#"+u.UserId, "PROFILE"
+	return nil
+}
+func (u *appUser) IndexKey(idx dynamox.Index) (pk, sk any, ok bool) {
+	return "EMAIL#" + u.Email, "USER#" + u.UserId, idx.Name() == gsi1.Name()
+}
+func (u *appUser) IndexKeySources(dynamox.Index) []string { return []string{"userId", "email"} }
+
+type appOrder struct {
+	appKey
+	UserId  string `dynamodbav:"userId"`
+	OrderId string `dynamodbav:"orderId"`
+	Status  string `dynamodbav:"status"`
+}
+
+func (o *appOrder) GetKeyBase() dynamox.KeyBase { return &o.appKey }
+func (o *appOrder) SaveSK() error {
+	o.Pk, o.Sk = "ORDER#"+o.OrderId, "PROFILE"
+	return nil
+}
+func (o *appOrder) IndexKey(idx dynamox.Index) (pk, sk any, ok bool) {
+	return "USER#" + o.UserId, "ORDER#" + o.Status + "#" + o.OrderId, idx.Name() == gsi1.Name()
+}
+func (o *appOrder) IndexKeySources(dynamox.Index) []string {
+	return []string{"userId", "orderId", "status"}
+}
+
+func Test_indexedItem(t *testing.T) {
+	order := &appOrder{UserId: "u1", OrderId: "o1", Status: "PAID"}
+	m, err := dynamox.MarshalMap(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pk, _ := m["GSI1PK"].(*types.AttributeValueMemberS); pk == nil || pk.Value != "USER#u1" {
+		t.Fatalf("unexpected GSI1PK: %v", m["GSI1PK"])
+	}
+	if sk, _ := m["GSI1SK"].(*types.AttributeValueMemberS); sk == nil || sk.Value != "ORDER#PAID#o1" {
+		t.Fatalf("unexpected GSI1SK: %v", m["GSI1SK"])
+	}
+
+	rec := &recorder{}
+	c := newRecordedClient(rec)
+	if err = c.Cruder().Update(t.Context(), order, false); err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	for _, name := range rec.bodies[0]["ExpressionAttributeNames"].(map[string]any) {
+		names[name.(string)] = true
+	}
+	if !names["GSI1PK"] || !names["GSI1SK"] || !strings.HasPrefix(rec.bodies[0]["UpdateExpression"].(string), "SET") {
+		t.Fatalf("unexpected update: %v", rec.bodies[0])
+	}
+
+	// a partial update leaves the index attributes whose sources it doesn't set
+	for _, tc := range []struct {
+		user    *appUser
+		indexed bool
+	}{
+		{&appUser{UserId: "u1"}, false},
+		{&appUser{UserId: "u1", Email: "a@b.c"}, true},
+	} {
+		if err = c.Cruder().Update(t.Context(), tc.user, false); err != nil {
+			t.Fatal(err)
+		}
+		names := map[string]bool{}
+		for _, name := range rec.bodies[len(rec.bodies)-1]["ExpressionAttributeNames"].(map[string]any) {
+			names[name.(string)] = true
+		}
+		if names["GSI1PK"] != tc.indexed || names["GSI1SK"] != tc.indexed {
+			t.Fatalf("%+v: unexpected index attributes %v", tc.user, names)
+		}
+	}
+
+	// a user and its orders in one index partition, told apart by GSI1SK
+	items := []map[string]types.AttributeValue{}
+	for _, item := range []dynamox.KeyedItem{
+		&appUser{UserId: "u1", Email: "a@b.c"},
+		order,
+		&appOrder{UserId: "u1", OrderId: "o2", Status: "SHIPPED"},
+	} {
+		m, err := dynamox.MarshalMap(item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, m)
+	}
+	rec.responses = append(rec.responses, queryResponse(t, items))
+	registry := dynamox.NewEntityRegistry().
+		RegisterByIndex((*appUser)(nil), gsi1, "USER#").
+		RegisterByIndex((*appOrder)(nil), gsi1, "ORDER#")
+	query := dynamox.NewCtxQuery(t.Context()).SetTable("App").SetEntityRegistry(registry).
+		SetKeyCondBuilder(dynamox.NewKeyCondBuilder().WithIndex(gsi1, "USER#u1"))
+	var decoded []dynamox.KeyedItem
+	if _, _, err = c.Query(query, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if query := rec.bodies[len(rec.bodies)-1]; query["IndexName"] != "GSI1" || len(decoded) != 3 {
+		t.Fatalf("unexpected query: %v, %d", query["IndexName"], len(decoded))
+	}
+	if u, ok := decoded[0].(*appUser); !ok || u.Email != "a@b.c" {
+		t.Fatalf("unexpected user: %#v", decoded[0])
+	}
+	if o, ok := decoded[2].(*appOrder); !ok || o.Status != "SHIPPED" {
+		t.Fatalf("unexpected order: %#v", decoded[2])
+	}
+}
+
+// queryResponse is the JSON protocol body of a Query output with items
+func queryResponse(t *testing.T, items []map[string]types.AttributeValue) string {
+	t.Helper()
+	list := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		m := make(map[string]any, len(item))
+		for k, v := range item {
+			switch v := v.(type) {
+			case *types.AttributeValueMemberS:
+				m[k] = map[string]string{"S": v.Value}
+			case *types.AttributeValueMemberN:
+				m[k] = map[string]string{"N": v.Value}
+			default:
+				t.Fatalf("unsupported attribute %s: %T", k, v)
+			}
+		}
+		list = append(list, m)
+	}
+	b, err := json.Marshal(map[string]any{"Items": list, "Count": len(list), "ScannedCount": len(list)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
@@ -27,7 +27,7 @@ func init() {
 			config.WithBaseEndpoint(localAddr),
 		)
 	)
-	cli = dynamox.NewClient(awsCfg)
+	cli = dynamox.NewClientWithOptions(awsCfg, func(o *dynamox.Options) { o.EntityRegistry = customerRegistry })
 }
 
 func Test_cleanup(t *testing.T) {
@@ -140,6 +140,50 @@ func (b *bundle) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error
 	return nil
 }
 
+var (
+	gsi_byEmail          = dynamox.MustGSI(dynsa.AttrDefS("email").Aws())
+	gsi_byUrl            = dynamox.MustGSI(dynsa.AttrDefS("url").Aws(), dynsa.AttrDefS("customerId").Aws())
+	gsi_byCustomerFolder = dynamox.MustGSI(dynsa.AttrDefS("customerId").Aws(), dynsa.AttrDefS("folder").Aws())
+)
+
+// ensureCustomerBookmark creates the CustomerBookmark table with its GSIs, whichever test runs first
+func ensureCustomerBookmark(t *testing.T) {
+	t.Helper()
+	table := base{}.Table()
+	exist, err := cli.TableExists(t.Context(), table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exist {
+		keybase := base{}
+
+		_, err = cli.SDK().CreateTable(t.Context(), &dynamodb.CreateTableInput{
+			TableName:                 aws.String(table),
+			DeletionProtectionEnabled: aws.Bool(false),
+			BillingMode:               types.BillingModePayPerRequest,
+			KeySchema: []types.KeySchemaElement{
+				{KeyType: types.KeyTypeHash, AttributeName: aws.String(keybase.PKField())},
+				{KeyType: types.KeyTypeRange, AttributeName: aws.String(keybase.SKField())},
+			},
+			GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+				{IndexName: aws.String(gsi_byEmail.Name()), KeySchema: gsi_byEmail.KeySchema(), Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll}},
+				{IndexName: aws.String(gsi_byUrl.Name()), KeySchema: gsi_byUrl.KeySchema(), Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll}},
+				{IndexName: aws.String(gsi_byCustomerFolder.Name()), KeySchema: gsi_byCustomerFolder.KeySchema(), Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll}},
+			},
+			AttributeDefinitions: []types.AttributeDefinition{
+				{AttributeName: aws.String(keybase.PKField()), AttributeType: types.ScalarAttributeTypeS},
+				{AttributeName: aws.String(keybase.SKField()), AttributeType: types.ScalarAttributeTypeS},
+				{AttributeName: aws.String(gsi_byEmail.PKField()), AttributeType: types.ScalarAttributeTypeS},
+				{AttributeName: aws.String(gsi_byUrl.PKField()), AttributeType: types.ScalarAttributeTypeS},
+				{AttributeName: aws.String(gsi_byCustomerFolder.SKField()), AttributeType: types.ScalarAttributeTypeS},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
 func Test_keyeditem(t *testing.T) {
 	var (
 		pk123 = base{CustomerId: "123"}
@@ -197,43 +241,9 @@ func Test_keyeditem(t *testing.T) {
 				Url:         "https://docs.aws.amazon.com",
 			},
 		}
-		gsi_byEmail          = dynamox.MustGSI(dynsa.AttrDefS("email").Aws())
-		gsi_byUrl            = dynamox.MustGSI(dynsa.AttrDefS("url").Aws(), dynsa.AttrDefS("customerId").Aws())
-		gsi_byCustomerFolder = dynamox.MustGSI(dynsa.AttrDefS("customerId").Aws(), dynsa.AttrDefS("folder").Aws())
 	)
 
-	exist, err := cli.TableExists(t.Context(), table)
-	if err != nil {
-		t.Fatal(err)
-	}
-	if !exist {
-		keybase := base{}
-
-		_, err = cli.SDK().CreateTable(t.Context(), &dynamodb.CreateTableInput{
-			TableName:                 aws.String(table),
-			DeletionProtectionEnabled: aws.Bool(false),
-			BillingMode:               types.BillingModePayPerRequest,
-			KeySchema: []types.KeySchemaElement{
-				{KeyType: types.KeyTypeHash, AttributeName: aws.String(keybase.PKField())},
-				{KeyType: types.KeyTypeRange, AttributeName: aws.String(keybase.SKField())},
-			},
-			GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
-				{IndexName: aws.String(gsi_byEmail.Name()), KeySchema: gsi_byEmail.KeySchema(), Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll}},
-				{IndexName: aws.String(gsi_byUrl.Name()), KeySchema: gsi_byUrl.KeySchema(), Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll}},
-				{IndexName: aws.String(gsi_byCustomerFolder.Name()), KeySchema: gsi_byCustomerFolder.KeySchema(), Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll}},
-			},
-			AttributeDefinitions: []types.AttributeDefinition{
-				{AttributeName: aws.String(keybase.PKField()), AttributeType: types.ScalarAttributeTypeS},
-				{AttributeName: aws.String(keybase.SKField()), AttributeType: types.ScalarAttributeTypeS},
-				{AttributeName: aws.String(gsi_byEmail.PKField()), AttributeType: types.ScalarAttributeTypeS},
-				{AttributeName: aws.String(gsi_byUrl.PKField()), AttributeType: types.ScalarAttributeTypeS},
-				{AttributeName: aws.String(gsi_byCustomerFolder.SKField()), AttributeType: types.ScalarAttributeTypeS},
-			},
-		})
-		if err != nil {
-			t.Fatal(err)
-		}
-	}
+	ensureCustomerBookmark(t)
 	for _, v := range items {
 		// m, err := dynamox.MarshalMap(v)
 		// if err != nil {
@@ -248,7 +258,7 @@ func Test_keyeditem(t *testing.T) {
 		}
 	}
 	profileKeyForRead := profile{base: pk123}
-	err = cli.Cruder().Read(t.Context(), &profileKeyForRead)
+	err := cli.Cruder().Read(t.Context(), &profileKeyForRead)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -282,6 +292,26 @@ func Test_keyeditem(t *testing.T) {
 	if _, _, err = cli.Query(query321, &bundle321); err != nil {
 		panic(err)
 	}
+	// pk123 with registry
+	var (
+		items123      []dynamox.KeyedItem
+		queryRegistry = dynamox.NewCtxQuery(t.Context()).SetTable(table).SetEntityRegistry(customerRegistry).SetKeyCondBuilder(
+			dynamox.NewKeyCondBuilder().WithPK(pk123.PKField(), pk123.PK()))
+	)
+	if _, _, err = cli.Query(queryRegistry, &items123); err != nil {
+		t.Fatal(err)
+	}
+	if len(items123) != bundle123.Count() {
+		t.Fatal("registry decoded count mismatch")
+	}
+	// pk321 with ReadPartition
+	partition321, err := cli.Cruder().ReadPartition(t.Context(), pk321)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dynamox.BundleItems[*bookmark](partition321)) != len(bundle321.Bookmarks) {
+		t.Fatal("ReadPartition bookmark count mismatch")
+	}
 	// ByUrl
 	var (
 		bookmarks      []bookmark
@@ -293,4 +323,17 @@ func Test_keyeditem(t *testing.T) {
 	if _, _, err = cli.Query(queryBookmarks, &bookmarks); err != nil {
 		panic(err)
 	}
+	// ByUrl with WithIndex, the index name out of the key condition
+	var (
+		indexBookmarks []bookmark
+		queryIndex     = dynamox.NewCtxQuery(t.Context()).
+				SetTable(table).
+				SetKeyCondBuilder(dynamox.NewKeyCondBuilder().WithIndex(gsi_byUrl, "https://aws.amazon.com"))
+	)
+	if _, _, err = cli.Query(queryIndex, &indexBookmarks); err != nil {
+		t.Fatal(err)
+	}
+	if len(indexBookmarks) != len(bookmarks) {
+		t.Fatal("WithIndex bookmark count mismatch")
+	}
 }
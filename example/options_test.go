@@ -0,0 +1,152 @@
+package example
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-chujang/dynamox"
+)
+
+// recorder answers DynamoDB calls with responses in order, then with an empty result,
+// keeping the request bodies
+type recorder struct {
+	bodies    []map[string]any
+	responses []string
+}
+
+func (r *recorder) Do(req *http.Request) (*http.Response, error) {
+	var body map[string]any
+	b, _ := io.ReadAll(req.Body)
+	_ = json.Unmarshal(b, &body)
+	r.bodies = append(r.bodies, body)
+	response := "{}"
+	if len(r.responses) > 0 {
+		response, r.responses = r.responses[0], r.responses[1:]
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.0"}},
+		Body:       io.NopCloser(strings.NewReader(response)),
+		Request:    req,
+	}, nil
+}
+
+type event struct {
+	base
+	dynamox.Model
+	EventId dynamox.ID    `dynamodbav:"eventId"`
+	Seq     dynamox.IDSeq `dynamodbav:"seq"`
+}
+
+func (e *event) GetKeyBase() dynamox.KeyBase { return &e.base }
+func (e *event) SaveSK() error               { return e.SaveSKWith(dynamox.GlobalConventions()) }
+func (e *event) SaveSKWith(cv dynamox.Conventions) error {
+	if e.Sk == "" {
+		e.Sk = cv.Composite("EVENT", e.Seq.String())
+	}
+	return nil
+}
+
+func Test_options(t *testing.T) {
+	var (
+		rec = &recorder{}
+		at  = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		c   = newRecordedClient(rec, func(o *dynamox.Options) {
+			o.Clock = dynamox.NewFakeClock(at)
+			o.IDGenerator = dynamox.IDGeneratorFunc(func() string { return "event-1" })
+			o.TableName = func(table string) string { return "test-" + table }
+			o.ConsistentRead = true
+			o.RetryMaxAttempts = 1
+			o.TimestampUnit = dynamox.Seconds
+			o.CompositeKeySep = "|"
+		})
+	)
+	if c.Now() != dynamox.Time(at.Unix()) || c.Options().RetryMaxAttempts != 1 {
+		t.Fatal("unexpected client options")
+	}
+	if dynamox.TimestampUnit() == dynamox.Seconds || dynamox.CompositeKeySep() == "|" {
+		t.Fatal("client options leaked into the globals")
+	}
+	if key := c.Composite("A", "b", "c"); key != "A|b|c" || !slices.Equal(c.SplitCompositeKey(key), []string{"A", "b", "c"}) {
+		t.Fatalf("unexpected client composite: %s", key)
+	}
+
+	item := &event{base: base{CustomerId: "123"}}
+	if err := c.Cruder().Create(t.Context(), item, false); err != nil {
+		t.Fatal(err)
+	}
+	if item.EventId != "event-1" || item.Seq == "" || item.CreatedAt.Int64() != at.Unix() || item.Sk != "EVENT|"+item.Seq.String() {
+		t.Fatalf("defaults not written back: %+v", item)
+	}
+	if err := c.Cruder().Update(t.Context(), item, false); err != nil {
+		t.Fatal(err)
+	}
+	if item.UpdatedAt != dynamox.Time(at.Unix()) {
+		t.Fatalf("unexpected updatedAt: %d", item.UpdatedAt)
+	}
+	_ = c.Cruder().Read(t.Context(), item)
+
+	if len(rec.bodies) != 3 {
+		t.Fatalf("unexpected requests: %d", len(rec.bodies))
+	}
+	for _, body := range rec.bodies {
+		if body["TableName"] != "test-CustomerBookmark" {
+			t.Fatalf("unexpected table: %v", body["TableName"])
+		}
+	}
+	if rec.bodies[2]["ConsistentRead"] != true {
+		t.Fatal("default ConsistentRead not applied")
+	}
+
+	tagged, err := c.MarshalMap(dynamox.MustTagged(&taggedProfile{CustomerId: "123"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sk := tagged["sk"].(*types.AttributeValueMemberS).Value; sk != "CUST|123" {
+		t.Fatalf("tagged sort key without the client separator: %s", sk)
+	}
+
+	// batch and transaction queries resolve their table names too
+	key, err := c.MarshalMapOnlyKey(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.bodies, rec.responses = nil, []string{`{"Responses":{"test-CustomerBookmark":[{"customerId":{"S":"123"}}]}}`}
+	responses, err := c.BatchGet(dynamox.NewCtxQuery(t.Context()).SetBatchGetItems(map[string]types.KeysAndAttributes{
+		item.Table(): {Keys: []map[string]types.AttributeValue{key}},
+	}))
+	if err != nil || len(responses[item.Table()]) != 1 {
+		t.Fatalf("batch get responses not keyed by the logical table: %v, %v", responses, err)
+	}
+	err = c.TransactionWrite(dynamox.NewCtxQuery(t.Context()).SetTransactionWriteItems([]types.TransactWriteItem{
+		{Delete: &types.Delete{TableName: aws.String(item.Table()), Key: key}},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rec.bodies[0]["RequestItems"].(map[string]any)["test-CustomerBookmark"]; !ok {
+		t.Fatalf("unexpected batch get: %v", rec.bodies[0])
+	}
+	if table := rec.bodies[1]["TransactItems"].([]any)[0].(map[string]any)["Delete"].(map[string]any)["TableName"]; table != "test-CustomerBookmark" {
+		t.Fatalf("unexpected transaction table: %v", table)
+	}
+}
+
+func newRecordedClient(rec *recorder, optFns ...func(*dynamox.Options)) *dynamox.Client {
+	return dynamox.NewClientWithOptions(aws.Config{
+		Region:      "ap-northeast-2",
+		Credentials: credentials.NewStaticCredentialsProvider("test", "test", ""),
+	}, append(optFns, dynamox.WithSDKOptions(func(o *dynamodb.Options) {
+		o.HTTPClient = rec
+		o.BaseEndpoint = aws.String("http://localhost:8000")
+	}))...)
+}
@@ -0,0 +1,80 @@
+package example
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-chujang/dynamox"
+)
+
+func Test_paginationKey(t *testing.T) {
+	// LastEvaluatedKey of an index query: table keys plus index keys
+	lastKey := dynamox.PaginationKey{
+		"pk":         &types.AttributeValueMemberS{Value: "ORDER#1"},
+		"sk":         &types.AttributeValueMemberS{Value: ""},
+		"openUserId": &types.AttributeValueMemberB{Value: []byte{0, 1, 2}},
+		"openedAt":   &types.AttributeValueMemberN{Value: "1714564800"},
+	}
+	token, err := lastKey.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pgk dynamox.PaginationKey
+	imported, err := pgk.Import(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(imported, lastKey) {
+		t.Fatalf("unexpected round trip: %v", imported)
+	}
+	b, err := json.Marshal(lastKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = json.Unmarshal(b, &pgk); err != nil || !reflect.DeepEqual(pgk, lastKey) {
+		t.Fatalf("unexpected JSON round trip: %v %v", pgk, err)
+	}
+
+	// the legacy JSON form stays readable
+	legacy := base64.URLEncoding.EncodeToString([]byte(`{"pk":{"S":"ORDER#1"},"sk":{"S":""},"openedAt":{"N":"1714564800"}}`))
+	if imported, err = pgk.Import(legacy); err != nil {
+		t.Fatal(err)
+	}
+	if len(imported) != 3 || imported["sk"].(*types.AttributeValueMemberS).Value != "" {
+		t.Fatalf("unexpected legacy import: %v", imported)
+	}
+
+	v1 := func(b ...byte) string {
+		return base64.RawURLEncoding.EncodeToString(append([]byte{dynamox.PaginationTokenV1}, b...))
+	}
+	for _, tc := range []struct {
+		token string
+		err   error
+	}{
+		{"not base64!", dynamox.ErrMalformedPaginationToken},
+		{base64.RawURLEncoding.EncodeToString([]byte{9}), dynamox.ErrUnsupportedTokenVersion},
+		{v1(1, 2, 'p', 'k', 'S', 5, 'a'), dynamox.ErrTruncatedPaginationToken},
+		{v1(1, 2, 'p', 'k', 'X', 1, 'a'), dynamox.ErrUnsupportedAttrValueTypeForKey},
+		{v1(1, 2, 'p', 'k', 'S', 1, 'a', 0), dynamox.ErrInvalidPaginationKey},
+		{v1(2, 2, 'p', 'k', 'S', 1, 'a', 2, 'p', 'k', 'S', 1, 'b'), dynamox.ErrInvalidPaginationKey},
+		{base64.URLEncoding.EncodeToString([]byte(`{"pk":{"L":"x"}}`)), dynamox.ErrUnsupportedAttrValueTypeForKey},
+	} {
+		if _, err = pgk.Import(tc.token); !errors.Is(err, tc.err) {
+			t.Fatalf("%q: expected %v, got %v", tc.token, tc.err, err)
+		}
+	}
+
+	// a sealed token isn't a plain one
+	ring, _ := dynamox.NewTokenKeyRing("k", []byte("0123456789abcdef"))
+	sealed, err := dynamox.NewTokenSealer(ring, dynamox.TokenSigned).Seal(lastKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = pgk.Import(sealed); !errors.Is(err, dynamox.ErrUnsupportedTokenVersion) {
+		t.Fatalf("expected ErrUnsupportedTokenVersion, got %v", err)
+	}
+}
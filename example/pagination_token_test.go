@@ -0,0 +1,86 @@
+package example
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-chujang/dynamox"
+)
+
+func Test_paginationToken(t *testing.T) {
+	ring, err := dynamox.NewTokenKeyRing("2024-05", []byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = dynamox.NewTokenKeyRing("short", []byte("secret")); !errors.Is(err, dynamox.ErrInvalidTokenKey) {
+		t.Fatalf("expected ErrInvalidTokenKey, got %v", err)
+	}
+	lastKey := dynamox.PaginationKey{
+		"pk": &types.AttributeValueMemberS{Value: "TENANT#1"},
+		"sk": &types.AttributeValueMemberS{Value: "ORDER#42"},
+	}
+	tenantQuery := func(tenant string) *dynamox.CtxQuery {
+		return dynamox.NewCtxQuery(t.Context()).SetTable("App").
+			SetKeyCondBuilder(dynamox.NewKeyCondBuilder().WithPK("pk", tenant))
+	}
+
+	for _, mode := range []dynamox.TokenMode{dynamox.TokenSigned, dynamox.TokenEncrypted} {
+		sealer := dynamox.NewTokenSealer(ring, mode)
+		token, err := sealer.SealNext(tenantQuery("TENANT#1"), lastKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rec := &recorder{}
+		c := newRecordedClient(rec)
+		if _, _, err = c.Query(tenantQuery("TENANT#1").SetStartToken(token, sealer), &[]appOrder{}); err != nil {
+			t.Fatal(err)
+		}
+		start := rec.bodies[0]["ExclusiveStartKey"].(map[string]any)
+		if start["sk"].(map[string]any)["S"] != "ORDER#42" {
+			t.Fatalf("mode %d: unexpected ExclusiveStartKey %v", mode, start)
+		}
+
+		// replayed against another partition
+		if _, _, err = c.Query(tenantQuery("TENANT#2").SetStartToken(token, sealer), &[]appOrder{}); !errors.Is(err, dynamox.ErrForgedPaginationToken) {
+			t.Fatalf("mode %d: expected ErrForgedPaginationToken, got %v", mode, err)
+		}
+		// tampered
+		tampered := []byte(token)
+		tampered[len(tampered)-2] ^= 'A' ^ 'B'
+		if _, _, err = c.Query(tenantQuery("TENANT#1").SetStartToken(string(tampered), sealer), &[]appOrder{}); err == nil {
+			t.Fatalf("mode %d: expected tampered token rejected", mode)
+		}
+		// a plain PaginationKey token isn't accepted
+		plain, _ := lastKey.MarshalJSON()
+		if _, _, err = c.Query(tenantQuery("TENANT#1").SetStartToken(string(plain[1:len(plain)-1]), sealer), &[]appOrder{}); !errors.Is(err, dynamox.ErrMalformedPaginationToken) {
+			t.Fatalf("mode %d: expected ErrMalformedPaginationToken, got %v", mode, err)
+		}
+		if len(rec.bodies) != 1 {
+			t.Fatalf("mode %d: rejected tokens must fail before sending", mode)
+		}
+	}
+
+	// rotation: old tokens open until the key is retired
+	sealer := dynamox.NewTokenSealer(ring, dynamox.TokenEncrypted)
+	old, err := sealer.Seal(lastKey, []byte("fp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ring.Rotate("2024-06", []byte("fedcba9876543210")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = sealer.Open(old, []byte("fp")); err != nil {
+		t.Fatalf("expected rotated key to open: %v", err)
+	}
+	if err = ring.Retire("2024-06"); !errors.Is(err, dynamox.ErrInvalidTokenKey) {
+		t.Fatalf("expected the active key kept, got %v", err)
+	}
+	if err = ring.Retire("2024-05"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = sealer.Open(old, []byte("fp")); !errors.Is(err, dynamox.ErrUnknownTokenKey) {
+		t.Fatalf("expected ErrUnknownTokenKey, got %v", err)
+	}
+}
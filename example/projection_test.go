@@ -0,0 +1,96 @@
+package example
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-chujang/dynamox"
+)
+
+// eventSummary is a narrow view of event, without key attributes
+type eventSummary struct {
+	dynamox.Model
+	EventId string `dynamodbav:"eventId"`
+	Note    string `dynamodbav:"-"`
+}
+
+func projectedAttrs(body map[string]any) []string {
+	names, _ := body["ExpressionAttributeNames"].(map[string]any)
+	proj, _ := body["ProjectionExpression"].(string)
+	var attrs []string
+	for _, holder := range strings.Split(proj, ", ") {
+		if name, ok := names[holder].(string); ok {
+			attrs = append(attrs, name)
+		}
+	}
+	slices.Sort(attrs)
+	return attrs
+}
+
+func Test_projection(t *testing.T) {
+	attrs, err := dynamox.ProjectionOf(&[]*event{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// base, Model (CreatedAtOnly embedded twice deep) and the fields of event, in field order
+	if expected := []string{"customerId", "sk", "createdAt", "updatedAt", "deletedAt", "eventId", "seq"}; !slices.Equal(attrs, expected) {
+		t.Fatalf("unexpected attrs: %v", attrs)
+	}
+	if _, err = dynamox.ProjectionOf(&[]dynamox.KeyedItem{}); !errors.Is(err, dynamox.ErrUnderivableProjection) {
+		t.Fatalf("expected ErrUnderivableProjection, got %v", err)
+	}
+
+	var (
+		rec      = &recorder{}
+		c        = newRecordedClient(rec)
+		ctx      = t.Context()
+		summary  eventSummary
+		expected = []string{"createdAt", "customerId", "deletedAt", "eventId", "sk", "updatedAt"}
+	)
+	key, _ := dynamox.MarshalMapOnlyKey(&event{base: base{CustomerId: "123", Sk: "EVENT#1"}})
+
+	// Get: keys of the query
+	_ = c.Get(dynamox.NewCtxQuery(ctx).SetTable("CustomerBookmark").SetKey(key).SetProjectionOf(&summary), &summary)
+	// Query: keys of the key condition, merged with the names of the expression
+	kcb := dynamox.NewKeyCondBuilder().WithPK("customerId", "123").WithSK(dynamox.BeginsWith, "sk", "EVENT#").
+		WithFilter(dynamox.Attr("eventId").Exists())
+	_, _, _ = c.Query(dynamox.NewCtxQuery(ctx).SetTable("CustomerBookmark").SetKeyCondBuilder(kcb).
+		SetSelectAttr(types.SelectAllAttributes).SetProjectionOf(&[]eventSummary{}), &[]eventSummary{})
+	// Scan: the type only, including the keys of a KeyedItem
+	_, _, _ = c.Scan(dynamox.NewCtxQuery(ctx).SetTable("CustomerBookmark").SetProjectionOf(&[]event{}), &[]event{})
+	// BatchGet: keys of the request
+	_, _ = c.BatchGet(dynamox.NewCtxQuery(ctx).SetBatchGetItems(map[string]types.KeysAndAttributes{
+		"CustomerBookmark": {Keys: []map[string]types.AttributeValue{key}},
+	}).SetProjectionOf(eventSummary{}))
+	// explicit projection wins
+	_ = c.Get(dynamox.NewCtxQuery(ctx).SetTable("CustomerBookmark").SetKey(key).
+		SetProjectExpr(aws.String("eventId")).SetProjectionOf(&summary), &summary)
+
+	if len(rec.bodies) != 5 {
+		t.Fatalf("unexpected requests: %d", len(rec.bodies))
+	}
+	if attrs := projectedAttrs(rec.bodies[0]); !slices.Equal(attrs, expected) {
+		t.Fatalf("unexpected get projection: %v", attrs)
+	}
+	query := rec.bodies[1]
+	if attrs := projectedAttrs(query); !slices.Equal(attrs, expected) {
+		t.Fatalf("unexpected query projection: %v", attrs)
+	}
+	if query["Select"] != string(types.SelectSpecificAttributes) || query["FilterExpression"] == nil {
+		t.Fatalf("unexpected query: %v", query)
+	}
+	if attrs := projectedAttrs(rec.bodies[2]); len(attrs) != 7 {
+		t.Fatalf("unexpected scan projection: %v", attrs)
+	}
+	items, _ := rec.bodies[3]["RequestItems"].(map[string]any)
+	if attrs := projectedAttrs(items["CustomerBookmark"].(map[string]any)); !slices.Equal(attrs, expected) {
+		t.Fatalf("unexpected batchGet projection: %v", attrs)
+	}
+	if rec.bodies[4]["ProjectionExpression"] != "eventId" {
+		t.Fatalf("unexpected explicit projection: %v", rec.bodies[4]["ProjectionExpression"])
+	}
+}
@@ -0,0 +1,77 @@
+package example
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-chujang/dynamox"
+)
+
+var customerRegistry = dynamox.NewEntityRegistry().
+	Register((*profile)(nil), profileSortKeyPrefix).
+	Register((*bookmark)(nil), "http")
+
+func Test_registry(t *testing.T) {
+	pk := base{CustomerId: "123"}
+	var l []map[string]types.AttributeValue
+	for _, v := range []dynamox.KeyedItem{
+		&profile{base: pk, Email: "shirley@example.net"},
+		&bookmark{base: pk, Url: "https://aws.amazon.com"},
+		&bookmark{base: pk, Url: "https://console.aws.amazon.com"},
+	} {
+		m, err := dynamox.MarshalMap(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l = append(l, m)
+	}
+
+	items, err := customerRegistry.DecodeList(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := items[0].(*profile); !ok || p.Email != "shirley@example.net" {
+		t.Fatalf("unexpected %T", items[0])
+	}
+	if b, ok := items[2].(*bookmark); !ok || b.Url != "https://console.aws.amazon.com" {
+		t.Fatalf("unexpected %T", items[2])
+	}
+
+	bundle := dynamox.NewBundle(customerRegistry, &pk)
+	if err = bundle.UnmarshalDynamoDBAttributeValue(listOfMaps(l)); err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := dynamox.BundleFirst[*profile](bundle); !ok || p.Email != "shirley@example.net" {
+		t.Fatal("unexpected bundle profile")
+	}
+	if bookmarks := dynamox.BundleItems[*bookmark](bundle); len(bookmarks) != 2 || bundle.Count() != 3 || bundle.PK() != pk.PK() {
+		t.Fatal("unexpected bundle bookmarks")
+	}
+
+	// an empty partition keeps the given KeyBase
+	c := newRecordedClient(&recorder{}, func(o *dynamox.Options) { o.EntityRegistry = customerRegistry })
+	empty, err := c.Cruder().ReadPartition(t.Context(), base{CustomerId: "404"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty.Count() != 0 || empty.PK() != "404" || empty.Table() != pk.Table() {
+		t.Fatal("unexpected empty partition")
+	}
+	if _, err = newRecordedClient(&recorder{}).Cruder().ReadPartition(t.Context(), pk); !errors.Is(err, dynamox.ErrRequiredEntityRegistry) {
+		t.Fatalf("expected ErrRequiredEntityRegistry, got %v", err)
+	}
+
+	l[0][pk.SKField()] = &types.AttributeValueMemberS{Value: "ORDER#1"}
+	if _, err = customerRegistry.DecodeList(l); !errors.Is(err, dynamox.ErrUnexpectedPartitionItem) {
+		t.Fatalf("expected ErrUnexpectedPartitionItem, got %v", err)
+	}
+}
+
+func listOfMaps(l []map[string]types.AttributeValue) types.AttributeValue {
+	av := &types.AttributeValueMemberL{Value: make([]types.AttributeValue, 0, len(l))}
+	for _, m := range l {
+		av.Value = append(av.Value, &types.AttributeValueMemberM{Value: m})
+	}
+	return av
+}
@@ -0,0 +1,118 @@
+package example
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-chujang/dynamox"
+	"github.com/go-chujang/dynamox/dynsa"
+)
+
+// openOrders indexes the open orders only
+var openOrders = dynamox.MustGSI(dynsa.AttrDefS("openUserId").Aws(), dynsa.AttrDefS("openedAt").Aws()).Sparse().WithKeysOnly()
+
+func init() { dynamox.RegisterIndexes("Shop", openOrders) }
+
+type shopOrder struct {
+	appKey
+	UserId   string `dynamodbav:"userId"`
+	OrderId  string `dynamodbav:"orderId"`
+	Status   string `dynamodbav:"status"`
+	OpenedAt string `dynamodbav:"openedAt,omitempty"`
+}
+
+func (shopOrder) Table() string                  { return "Shop" }
+func (o *shopOrder) GetKeyBase() dynamox.KeyBase { return o }
+func (o *shopOrder) SaveSK() error {
+	o.Pk, o.Sk = "ORDER#"+o.OrderId, "ORDER"
+	return nil
+}
+func (o *shopOrder) IndexKey(idx dynamox.Index) (pk, sk any, ok bool) {
+	return o.UserId, o.OpenedAt, idx.Name() == openOrders.Name() && o.Status == "OPEN"
+}
+func (o *shopOrder) IndexKeySources(dynamox.Index) []string {
+	return []string{"userId", "openedAt", "status"}
+}
+
+func Test_sparseIndex(t *testing.T) {
+	order := &shopOrder{UserId: "u1", OrderId: "o1", Status: "OPEN", OpenedAt: "2024-05-01"}
+	if !dynamox.IndexContains(openOrders, order) {
+		t.Fatal("expected open order in the index")
+	}
+	m, err := dynamox.MarshalMap(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m["openUserId"] == nil || m["openedAt"] == nil {
+		t.Fatalf("expected index attributes: %v", m)
+	}
+
+	// closing drops the order out of the index
+	order.Status = "CLOSED"
+	if dynamox.IndexContains(openOrders, order) {
+		t.Fatal("expected closed order out of the index")
+	}
+	if m, err = dynamox.MarshalMap(order); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m["openUserId"]; ok || m["openedAt"] == nil {
+		t.Fatalf("expected openUserId removed, openedAt of a struct field kept: %v", m)
+	}
+
+	rec := &recorder{}
+	c := newRecordedClient(rec)
+	if err = c.Cruder().Update(t.Context(), order, false); err != nil {
+		t.Fatal(err)
+	}
+	clauses := updateClauses(rec.bodies[0])
+	if removed := clauses["REMOVE"]; !removed["openUserId"] || len(removed) != 1 {
+		t.Fatalf("unexpected REMOVE: %v", removed)
+	}
+	if set := clauses["SET"]; !set["status"] || !set["openedAt"] {
+		t.Fatalf("unexpected SET: %v", set)
+	}
+
+	// a partial update without status leaves the index as stored, writing its own openedAt
+	partial := &shopOrder{UserId: "u1", OrderId: "o1", OpenedAt: "2024-06-01"}
+	if err = c.Cruder().Update(t.Context(), partial, false); err != nil {
+		t.Fatal(err)
+	}
+	clauses = updateClauses(rec.bodies[1])
+	if removed := clauses["REMOVE"]; len(removed) != 0 {
+		t.Fatalf("unexpected REMOVE of a partial update: %v", removed)
+	}
+	if set := clauses["SET"]; !set["openedAt"] || set["openUserId"] {
+		t.Fatalf("unexpected SET of a partial update: %v", set)
+	}
+
+	// non-sparse indexes are left untouched by the predicate
+	if !dynamox.IndexContains(gsi1, &appOrder{}) || dynamox.IndexContains(openOrders, &appOrder{}) {
+		t.Fatal("unexpected IndexContains")
+	}
+
+	// reads through the sparse index
+	if _, _, err = c.Scan(dynamox.NewCtxQuery(t.Context()).SetTable("Shop").SetIndexOf(openOrders), &[]shopOrder{}); err != nil {
+		t.Fatal(err)
+	}
+	if rec.bodies[2]["IndexName"] != openOrders.Name() {
+		t.Fatalf("unexpected IndexName: %v", rec.bodies[2]["IndexName"])
+	}
+	if _, _, err = c.Scan(dynamox.NewCtxQuery(t.Context()).SetTable("Shop").SetIndexOf(openOrders).SetConsistentRead(true), &[]shopOrder{}); err == nil {
+		t.Fatal("expected ErrConsistentReadOnGSI")
+	}
+}
+
+// updateClauses parses the UpdateExpression of body into SET / REMOVE -> attribute names
+func updateClauses(body map[string]any) map[string]map[string]bool {
+	clauses := map[string]map[string]bool{}
+	names := body["ExpressionAttributeNames"].(map[string]any)
+	for _, line := range strings.Split(strings.TrimSpace(body["UpdateExpression"].(string)), "\n") {
+		action, operands, _ := strings.Cut(line, " ")
+		clauses[action] = map[string]bool{}
+		for _, operand := range strings.Split(operands, ", ") {
+			holder, _, _ := strings.Cut(operand, " ")
+			clauses[action][names[holder].(string)] = true
+		}
+	}
+	return clauses
+}
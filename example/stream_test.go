@@ -0,0 +1,170 @@
+package example
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	streamtypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+	"github.com/go-chujang/dynamox"
+	"github.com/go-chujang/dynamox/dynstream"
+)
+
+func Test_stream(t *testing.T) {
+	var (
+		ctx    = t.Context()
+		stream = dynstream.NewMemoryStream("arn:aws:dynamodb:local:000000000000:table/CustomerBookmark/stream/local")
+		cp     = dynstream.NewMemoryCheckpointer()
+		image  = func(item dynamox.KeyedItem) map[string]types.AttributeValue {
+			m, err := dynamox.MarshalMap(item)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return m
+		}
+		keys = func(item dynamox.KeyedItem) map[string]types.AttributeValue {
+			m, err := dynamox.MarshalMapOnlyKey(item)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return m
+		}
+		put = func(shardID string, typ dynstream.EventType, key dynamox.KeyedItem, newItem, oldItem dynamox.KeyedItem) {
+			var newImage, oldImage map[string]types.AttributeValue
+			if newItem != nil {
+				newImage = image(newItem)
+			}
+			if oldItem != nil {
+				oldImage = image(oldItem)
+			}
+			if _, err := stream.Put(shardID, typ, keys(key), newImage, oldImage); err != nil {
+				t.Fatal(err)
+			}
+		}
+	)
+
+	pk := base{CustomerId: "123"}
+	v1 := &profile{base: pk, Email: "v1@example.net"}
+	v2 := &profile{base: pk, Email: "v2@example.net"}
+	bm := &bookmark{base: pk, Title: "AWS", Url: "https://aws.amazon.com"}
+
+	stream.AddShard("shard-0", "")
+	put("shard-0", dynstream.Insert, v1, v1, nil)
+	put("shard-0", dynstream.Insert, bm, bm, nil)
+	if err := stream.Split("shard-0", "shard-1", "shard-2"); err != nil {
+		t.Fatal(err)
+	}
+	put("shard-1", dynstream.Modify, v2, v2, v1)
+	put("shard-2", dynstream.Remove, bm, nil, bm)
+
+	var (
+		profiles  []dynstream.Change[*profile]
+		bookmarks []dynstream.Change[*bookmark]
+		newProc   = func() *dynstream.Processor {
+			p := dynstream.NewProcessor(stream, stream.StreamArn(), cp, func(o *dynstream.Options) { o.Limit = 1 })
+			dynstream.Handle[profile](p, dynstream.MatchSKPrefix(pk.SKField(), profileSortKeyPrefix),
+				func(_ context.Context, c dynstream.Change[*profile]) error {
+					profiles = append(profiles, c)
+					return nil
+				})
+			dynstream.Handle[bookmark](p, dynstream.MatchSKPrefix(pk.SKField(), "http"),
+				func(_ context.Context, c dynstream.Change[*bookmark]) error {
+					bookmarks = append(bookmarks, c)
+					return nil
+				})
+			return p
+		}
+		drain = func(p *dynstream.Processor) {
+			for range 10 {
+				if _, err := p.Step(ctx); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	)
+
+	proc := newProc()
+	if _, err := proc.Step(ctx); err != nil {
+		t.Fatal(err)
+	}
+	stream.ExpireIterators()
+	drain(proc)
+
+	if len(profiles) != 2 || len(bookmarks) != 2 {
+		t.Fatalf("unexpected events: profiles=%d bookmarks=%d", len(profiles), len(bookmarks))
+	}
+	if profiles[0].Type != dynstream.Insert || profiles[0].New.Email != v1.Email || profiles[0].Old != nil {
+		t.Fatal("unexpected insert event")
+	}
+	if profiles[1].Type != dynstream.Modify || profiles[1].New.Email != v2.Email || profiles[1].Old.Email != v1.Email {
+		t.Fatal("unexpected modify event")
+	}
+	if bookmarks[1].Type != dynstream.Remove || bookmarks[1].New != nil || bookmarks[1].Old.Title != bm.Title {
+		t.Fatal("unexpected remove event")
+	}
+	if profiles[0].SequenceNumber > profiles[1].SequenceNumber {
+		t.Fatal("child shard delivered before parent")
+	}
+
+	// the drained parent is dropped once its children are known
+	if shards := proc.Shards(); !slices.Equal(shards, []string{"shard-1", "shard-2"}) {
+		t.Fatalf("unexpected tracked shards: %v", shards)
+	}
+
+	// restart from checkpoints, nothing is redelivered
+	profiles, bookmarks = nil, nil
+	drain(newProc())
+	if len(profiles) != 0 || len(bookmarks) != 0 {
+		t.Fatal("redelivered checkpointed records")
+	}
+
+	// LATEST skips the backlog of the shards open at startup, not the records of a later split
+	stream = dynstream.NewMemoryStream("arn:aws:dynamodb:local:000000000000:table/CustomerBookmark/stream/latest")
+	stream.AddShard("shard-0", "")
+	put("shard-0", dynstream.Insert, v1, v1, nil)
+	latest := dynstream.NewProcessor(stream, stream.StreamArn(), dynstream.NewMemoryCheckpointer(), func(o *dynstream.Options) {
+		o.StartingPosition = streamtypes.ShardIteratorTypeLatest
+		o.RefreshInterval = 0
+	})
+	profiles = nil
+	dynstream.Handle[profile](latest, dynstream.MatchSKPrefix(pk.SKField(), profileSortKeyPrefix),
+		func(_ context.Context, c dynstream.Change[*profile]) error {
+			profiles = append(profiles, c)
+			return nil
+		})
+	if _, err := latest.Step(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Split("shard-0", "shard-1"); err != nil {
+		t.Fatal(err)
+	}
+	put("shard-1", dynstream.Modify, v2, v2, v1)
+	drain(latest)
+	if len(profiles) != 1 || profiles[0].Type != dynstream.Modify {
+		t.Fatalf("expected only the record of the split child, got %d", len(profiles))
+	}
+
+	// a checkpoint past the retention fails the processor, unless Trimmed lets it restart
+	stream = dynstream.NewMemoryStream("arn:aws:dynamodb:local:000000000000:table/CustomerBookmark/stream/trimmed")
+	stream.AddShard("shard-0", "")
+	put("shard-0", dynstream.Insert, v1, v1, nil)
+	cp = dynstream.NewMemoryCheckpointer()
+	if err := cp.Save(ctx, stream.StreamArn(), "shard-0", dynstream.Checkpoint{SequenceNumber: "trimmed"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dynstream.NewProcessor(stream, stream.StreamArn(), cp).Step(ctx); !errors.Is(err, dynstream.ErrCheckpointTrimmed) {
+		t.Fatalf("expected ErrCheckpointTrimmed, got %v", err)
+	}
+	var lost []string
+	restarted := dynstream.NewProcessor(stream, stream.StreamArn(), cp, func(o *dynstream.Options) {
+		o.Trimmed = func(_ context.Context, shardID, sequenceNumber string) error {
+			lost = append(lost, shardID+"@"+sequenceNumber)
+			return nil
+		}
+	})
+	if n, err := restarted.Step(ctx); err != nil || n != 1 || !slices.Equal(lost, []string{"shard-0@trimmed"}) {
+		t.Fatalf("expected a restart at TRIM_HORIZON, got %d, %v, %v", n, lost, err)
+	}
+}
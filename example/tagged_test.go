@@ -0,0 +1,114 @@
+package example
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-chujang/dynamox"
+)
+
+type taggedProfile struct {
+	CustomerId string `dynamodbav:"customerId" dynamox:"pk"`
+	Sk         string `dynamodbav:"sk" dynamox:"sk,prefix=CUST,from=CustomerId"`
+	Email      string `dynamodbav:"email"`
+}
+
+type taggedNote struct {
+	NoteId string `dynamodbav:"noteId" dynamox:"pk"`
+	Sk     string `dynamodbav:"sk" dynamox:"sk,prefix=NOTE,from=NoteId"`
+	Body   string `dynamodbav:"body"`
+	dynamox.Model
+}
+
+func init() {
+	dynamox.RegisterTable((*taggedProfile)(nil), "CustomerBookmark")
+	dynamox.RegisterTable((*taggedNote)(nil), "Notes")
+}
+
+func Test_tagged(t *testing.T) {
+	item := dynamox.MustTagged(&taggedProfile{CustomerId: "123", Email: "shirley@example.net"})
+	if item.Table() != "CustomerBookmark" || item.PKField() != "customerId" || item.SKField() != "sk" {
+		t.Fatal("unexpected tagged key fields")
+	}
+
+	m, err := dynamox.MarshalMap(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sk, ok := m["sk"].(*types.AttributeValueMemberS); !ok || sk.Value != profileSortKeyPrefix.Composite("123") || len(m) != 3 {
+		t.Fatalf("unexpected marshaled item: %v", m)
+	}
+	key, err := dynamox.MarshalMapOnlyKey(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != 2 {
+		t.Fatalf("unexpected key: %v", key)
+	}
+	if _, err = dynamox.NewKeyCondBuilder().WithKeyBase(item, dynamox.BeginsWith).Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	var out taggedProfile
+	if err = dynamox.UnmarshalMap(m, dynamox.MustTagged(&out)); err != nil {
+		t.Fatal(err)
+	}
+	if out.Email != "shirley@example.net" || out.Sk != profileSortKeyPrefix.Composite("123") {
+		t.Fatal("unexpected unmarshaled item")
+	}
+
+	registry := dynamox.NewEntityRegistry().Register(dynamox.MustTagged(&taggedProfile{}), profileSortKeyPrefix)
+	decoded, err := registry.Decode(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := dynamox.Untag(decoded).(*taggedProfile); !ok || p.Email != out.Email {
+		t.Fatalf("unexpected decoded %T", dynamox.Untag(decoded))
+	}
+	bundle := dynamox.NewBundle(registry, &base{CustomerId: "123"})
+	if err = bundle.UnmarshalDynamoDBAttributeValue(listOfMaps([]map[string]types.AttributeValue{m})); err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := dynamox.BundleFirst[*taggedProfile](bundle); !ok || p.Email != out.Email || len(dynamox.BundleItems[*taggedProfile](bundle)) != 1 {
+		t.Fatal("tagged entity not bundled under its own type")
+	}
+
+	if _, err = dynamox.MarshalMap(dynamox.MustTagged(&taggedProfile{})); !errors.Is(err, dynamox.ErrInsufficientCompositeKeySrc) {
+		t.Fatalf("expected ErrInsufficientCompositeKeySrc, got %v", err)
+	}
+	if _, err = dynamox.Tagged(&struct{ A string }{}); !errors.Is(err, dynamox.ErrInvalidKeyTag) {
+		t.Fatalf("expected ErrInvalidKeyTag, got %v", err)
+	}
+
+	// the cruder sees through the adapter: Model fields and the update of the struct's own fields
+	note := dynamox.MustTagged(&taggedNote{NoteId: "n1", Body: "hello"})
+	if !dynamox.HasModel(note) {
+		t.Fatal("expected Model embedded in the tagged struct")
+	}
+	if _, err = dynamox.KeyedItem2UpdateExpr(note); err != nil {
+		t.Fatal(err)
+	}
+	rec := &recorder{}
+	c := newRecordedClient(rec)
+	if err = c.Cruder().Update(t.Context(), note, false); err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	for _, name := range rec.bodies[0]["ExpressionAttributeNames"].(map[string]any) {
+		names[name.(string)] = true
+	}
+	if !names["body"] || !names["updatedAt"] || rec.bodies[0]["TableName"] != "Notes" {
+		t.Fatalf("unexpected update: %v", rec.bodies[0])
+	}
+	if err = c.Cruder().DeleteSoft(t.Context(), note); err != nil {
+		t.Fatal(err)
+	}
+	names = map[string]bool{}
+	for _, name := range rec.bodies[1]["ExpressionAttributeNames"].(map[string]any) {
+		names[name.(string)] = true
+	}
+	if !names["deletedAt"] {
+		t.Fatalf("unexpected soft delete: %v", rec.bodies[1])
+	}
+}
@@ -0,0 +1,75 @@
+package example
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/go-chujang/dynamox"
+)
+
+func Test_time(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 30, 15, 123456789, time.UTC)
+
+	dynamox.SetTimestampUnit(dynamox.Seconds)
+	if dynamox.TimeByTime(at).Int64() != at.Unix() || !dynamox.TimeByTime(at).Time().Equal(at.Truncate(time.Second)) {
+		t.Fatal("TimeByTime ignores the timestamp unit")
+	}
+	dynamox.SetTimestampUnit(dynamox.MilliSeconds)
+
+	type event struct {
+		At      dynamox.Time           `dynamodbav:"at" json:"at"`
+		Created dynamox.TimeDefaultNow `dynamodbav:"created" json:"created"`
+		Sec     dynamox.TimeSec        `dynamodbav:"sec" json:"sec"`
+		Milli   dynamox.TimeMilli      `dynamodbav:"milli" json:"milli"`
+		Micro   dynamox.TimeMicro      `dynamodbav:"micro" json:"micro"`
+		Nano    dynamox.TimeNano       `dynamodbav:"nano" json:"nano"`
+		RFC3339 dynamox.TimeRFC3339    `dynamodbav:"rfc3339" json:"rfc3339"`
+		Empty   dynamox.TimeRFC3339    `dynamodbav:"empty" json:"empty"`
+	}
+	in := event{
+		At:      dynamox.TimeByTime(at),
+		Sec:     dynamox.TimeSecOf(at),
+		Milli:   dynamox.TimeMilliOf(at),
+		Micro:   dynamox.TimeMicroOf(at),
+		Nano:    dynamox.TimeNanoOf(at),
+		RFC3339: dynamox.TimeRFC3339Of(at),
+	}
+	if !in.Sec.Time().Equal(at.Truncate(time.Second)) || !in.Micro.Time().Equal(at.Truncate(time.Microsecond)) ||
+		!in.Nano.Time().Equal(at) || !in.Milli.Time().Equal(in.At.Time()) {
+		t.Fatal("unexpected unit conversion")
+	}
+
+	m, err := attributevalue.MarshalMap(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m["empty"].(*types.AttributeValueMemberS); ok {
+		t.Fatal("zero TimeRFC3339 marshaled")
+	}
+	var fromDDB event
+	if err = attributevalue.UnmarshalMap(m, &fromDDB); err != nil {
+		t.Fatal(err)
+	}
+	if fromDDB.Created == 0 {
+		t.Fatal("TimeDefaultNow not set")
+	}
+	in.Created = fromDDB.Created
+
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON event
+	if err = json.Unmarshal(b, &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	for _, out := range []event{fromDDB, fromJSON} {
+		if out.At != in.At || out.Created != in.Created || out.Sec != in.Sec || out.Milli != in.Milli ||
+			out.Micro != in.Micro || out.Nano != in.Nano || !out.RFC3339.Equal(at) || !out.Empty.IsZero() {
+			t.Fatalf("unexpected roundtrip: %+v", out)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package dynamox
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+)
+
+// Filter is a typed filter condition, composed with KeyCondBuilder.WithFilter into one expression
+// so that the placeholders of key condition, filter and projection never collide.
+//
+//	filter := dynamox.And(
+//		dynamox.Attr("status").In("PAID", "SHIPPED"),
+//		dynamox.Attr("total").Ge(100),
+//		dynamox.Not(dynamox.Attr("deletedAt").Exists()),
+//	)
+//	NewKeyCondBuilder().WithPK("customerId", "123").WithFilter(filter)
+//
+// for scan: expr, _ := filter.Build(); NewCtxQuery(ctx).ExprScan(expr)
+type Filter struct {
+	cond  expression.ConditionBuilder
+	valid bool
+	err   error
+}
+
+// FilterAttr is the attribute name of a Filter, nested paths with dots ("address.city")
+type FilterAttr string
+
+// FilterSize is size(attr) of a Filter
+type FilterSize struct{ attr FilterAttr }
+
+type AttrType = expression.DynamoDBAttributeType
+
+const (
+	AttrTypeString    = expression.String
+	AttrTypeStringSet = expression.StringSet
+	AttrTypeNumber    = expression.Number
+	AttrTypeNumberSet = expression.NumberSet
+	AttrTypeBinary    = expression.Binary
+	AttrTypeBinarySet = expression.BinarySet
+	AttrTypeBoolean   = expression.Boolean
+	AttrTypeNull      = expression.Null
+	AttrTypeList      = expression.List
+	AttrTypeMap       = expression.Map
+)
+
+func Attr(name string) FilterAttr { return FilterAttr(name) }
+
+func (a FilterAttr) name() expression.NameBuilder { return expression.Name(string(a)) }
+
+func (a FilterAttr) filter(cond expression.ConditionBuilder) Filter {
+	if a == "" {
+		return Filter{err: ErrRequiredKeyAndValue}
+	}
+	return Filter{cond: cond, valid: true}
+}
+
+func (a FilterAttr) Eq(v any) Filter { return a.filter(a.name().Equal(expression.Value(v))) }
+func (a FilterAttr) Ne(v any) Filter { return a.filter(a.name().NotEqual(expression.Value(v))) }
+func (a FilterAttr) Lt(v any) Filter { return a.filter(a.name().LessThan(expression.Value(v))) }
+func (a FilterAttr) Le(v any) Filter { return a.filter(a.name().LessThanEqual(expression.Value(v))) }
+func (a FilterAttr) Gt(v any) Filter { return a.filter(a.name().GreaterThan(expression.Value(v))) }
+func (a FilterAttr) Ge(v any) Filter { return a.filter(a.name().GreaterThanEqual(expression.Value(v))) }
+
+func (a FilterAttr) Between(lower, upper any) Filter {
+	return a.filter(a.name().Between(expression.Value(lower), expression.Value(upper)))
+}
+
+// InOperandsLimit is the most values of an IN comparator DynamoDB accepts
+const InOperandsLimit = 100
+
+// In requires at least one value, up to InOperandsLimit
+func (a FilterAttr) In(values ...any) Filter {
+	switch {
+	case len(values) == 0:
+		return Filter{err: ErrRequiredKeyAndValue}
+	case len(values) > InOperandsLimit:
+		return Filter{err: fmt.Errorf("%w: %d values", ErrTooManyInOperands, len(values))}
+	}
+	operands := make([]expression.OperandBuilder, 0, len(values)-1)
+	for _, v := range values[1:] {
+		operands = append(operands, expression.Value(v))
+	}
+	return a.filter(a.name().In(expression.Value(values[0]), operands...))
+}
+
+func (a FilterAttr) BeginsWith(prefix string) Filter { return a.filter(a.name().BeginsWith(prefix)) }
+
+// Contains is a substring of S, or an element of a set or L
+func (a FilterAttr) Contains(v any) Filter {
+	return a.filter(expression.Contains(a.name(), v))
+}
+
+func (a FilterAttr) Exists() Filter    { return a.filter(a.name().AttributeExists()) }
+func (a FilterAttr) NotExists() Filter { return a.filter(a.name().AttributeNotExists()) }
+
+func (a FilterAttr) Type(typ AttrType) Filter { return a.filter(a.name().AttributeType(typ)) }
+
+func (a FilterAttr) Size() FilterSize { return FilterSize{attr: a} }
+
+// Cond is the conditionOperator form, as for KeyCondBuilder.WithSK
+func (a FilterAttr) Cond(cond conditionOperator, value any, betweenUpper ...any) Filter {
+	switch cond {
+	case LessThan:
+		return a.Lt(value)
+	case LessThanEqual:
+		return a.Le(value)
+	case GreaterThan:
+		return a.Gt(value)
+	case GreaterThanEqual:
+		return a.Ge(value)
+	case BeginsWith:
+		prefix, ok := value.(string)
+		if !ok {
+			return Filter{err: ErrBeginsWithPrefixType}
+		}
+		return a.BeginsWith(prefix)
+	case Between:
+		if len(betweenUpper) == 0 || betweenUpper[0] == nil {
+			return Filter{err: ErrBetweenUpperValue}
+		}
+		return a.Between(value, betweenUpper[0])
+	}
+	return a.Eq(value)
+}
+
+func (s FilterSize) size() expression.SizeBuilder { return s.attr.name().Size() }
+
+func (s FilterSize) Eq(n int) Filter {
+	return s.attr.filter(s.size().Equal(expression.Value(n)))
+}
+
+func (s FilterSize) Ne(n int) Filter {
+	return s.attr.filter(s.size().NotEqual(expression.Value(n)))
+}
+
+func (s FilterSize) Lt(n int) Filter {
+	return s.attr.filter(s.size().LessThan(expression.Value(n)))
+}
+
+func (s FilterSize) Le(n int) Filter {
+	return s.attr.filter(s.size().LessThanEqual(expression.Value(n)))
+}
+
+func (s FilterSize) Gt(n int) Filter {
+	return s.attr.filter(s.size().GreaterThan(expression.Value(n)))
+}
+
+func (s FilterSize) Ge(n int) Filter {
+	return s.attr.filter(s.size().GreaterThanEqual(expression.Value(n)))
+}
+
+func (s FilterSize) Between(lower, upper int) Filter {
+	return s.attr.filter(s.size().Between(expression.Value(lower), expression.Value(upper)))
+}
+
+/////////////////////////////////////////////////////////////////////////////
+
+// And skips empty filters, a single filter is returned as it is
+func And(filters ...Filter) Filter { return join(filters, expression.And) }
+
+// Or skips empty filters, a single filter is returned as it is
+func Or(filters ...Filter) Filter { return join(filters, expression.Or) }
+
+func Not(f Filter) Filter {
+	if f.err != nil || !f.valid {
+		return f
+	}
+	return Filter{cond: expression.Not(f.cond), valid: true}
+}
+
+func (f Filter) And(filters ...Filter) Filter { return And(append([]Filter{f}, filters...)...) }
+func (f Filter) Or(filters ...Filter) Filter  { return Or(append([]Filter{f}, filters...)...) }
+
+func join(filters []Filter, fn func(l, r expression.ConditionBuilder, other ...expression.ConditionBuilder) expression.ConditionBuilder) Filter {
+	conds := make([]expression.ConditionBuilder, 0, len(filters))
+	for _, f := range filters {
+		if f.err != nil {
+			return f
+		}
+		if f.valid {
+			conds = append(conds, f.cond)
+		}
+	}
+	switch len(conds) {
+	case 0:
+		return Filter{}
+	case 1:
+		return Filter{cond: conds[0], valid: true}
+	}
+	return Filter{cond: fn(conds[0], conds[1], conds[2:]...), valid: true}
+}
+
+// IsEmpty reports the zero Filter, or And / Or of nothing
+func (f Filter) IsEmpty() bool { return !f.valid && f.err == nil }
+
+func (f Filter) Err() error { return f.err }
+
+// Build is the filter-only expression, for ExprScan
+func (f Filter) Build() (expression.Expression, error) {
+	if f.err != nil {
+		return expression.Expression{}, f.err
+	}
+	if !f.valid {
+		return expression.Expression{}, nil
+	}
+	return expression.NewBuilder().WithFilter(f.cond).Build()
+}
@@ -1,7 +1,11 @@
 package dynamox
 
 import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
 type KeyCondBuilder struct {
@@ -12,6 +16,8 @@ type KeyCondBuilder struct {
 	sortKeyBetweenUpper any
 	sortKeyCondition    conditionOperator
 	projectionFieldList []string
+	filter              Filter
+	index               *Index
 	// partitionKeyCondition conditionOperator // always equal
 }
 
@@ -41,11 +47,46 @@ func (kcb *KeyCondBuilder) WithKeyBase(base KeyBase, skCond conditionOperator, b
 		WithSK(skCond, base.SKField(), base.SK(), betweenUpper...)
 }
 
+// WithIndex queries idx, binding pk to the partition key of the index
+func (kcb *KeyCondBuilder) WithIndex(idx Index, pk any) *KeyCondBuilder {
+	kcb.index = &idx
+	return kcb.WithPK(idx.PKField(), pk)
+}
+
+// WithIndexSK binds the sort key condition to the sort key of the index, after WithIndex
+func (kcb *KeyCondBuilder) WithIndexSK(cond conditionOperator, value any, betweenUpper ...any) *KeyCondBuilder {
+	var field string
+	if kcb.index != nil {
+		field = kcb.index.SKField()
+	}
+	kcb.sortKeyCondition = cond
+	kcb.sortKeyField = field
+	kcb.sortKeyValue = value
+	if len(betweenUpper) > 0 {
+		kcb.sortKeyBetweenUpper = betweenUpper[0]
+	}
+	return kcb
+}
+
+// Index returns the index set by WithIndex
+func (kcb KeyCondBuilder) Index() (Index, bool) {
+	if kcb.index == nil {
+		return Index{}, false
+	}
+	return *kcb.index, true
+}
+
 func (kcb *KeyCondBuilder) WithProj(projs ...string) *KeyCondBuilder {
 	kcb.projectionFieldList = append(kcb.projectionFieldList, projs...)
 	return kcb
 }
 
+// WithFilter ANDs filters to the previous ones, built into the same expression as the key condition
+func (kcb *KeyCondBuilder) WithFilter(filters ...Filter) *KeyCondBuilder {
+	kcb.filter = And(append([]Filter{kcb.filter}, filters...)...)
+	return kcb
+}
+
 func (kcb KeyCondBuilder) IsEnable() bool {
 	return len(kcb.partitionKeyField) > 0 && kcb.partitionKeyValue != nil
 }
@@ -55,6 +96,12 @@ func (kcb KeyCondBuilder) Build() (expression.Expression, error) {
 		return expression.Expression{}, ErrRequiredPartitionKey
 	}
 
+	if kcb.index != nil {
+		if err := kcb.validateIndexKey(); err != nil {
+			return expression.Expression{}, err
+		}
+	}
+
 	builder := expression.NewBuilder()
 	keyCond, err := Equal.keyCondBuilder(kcb.partitionKeyField, kcb.partitionKeyValue, nil)
 	if err != nil {
@@ -67,6 +114,12 @@ func (kcb KeyCondBuilder) Build() (expression.Expression, error) {
 		}
 		keyCond = keyCond.And(skCond)
 	}
+	if err := kcb.filter.Err(); err != nil {
+		return expression.Expression{}, err
+	}
+	if !kcb.filter.IsEmpty() {
+		builder = builder.WithFilter(kcb.filter.cond)
+	}
 	if l := len(kcb.projectionFieldList); l > 0 {
 		proj := expression.NamesList(expression.Name(kcb.projectionFieldList[0]))
 		if l > 1 {
@@ -83,3 +136,57 @@ func (kcb KeyCondBuilder) Build() (expression.Expression, error) {
 	}
 	return builder.WithKeyCondition(keyCond).Build()
 }
+
+// validateIndexKey checks the key fields, value types and sort key operator against the index
+func (kcb KeyCondBuilder) validateIndexKey() error {
+	idx := kcb.index
+	if kcb.partitionKeyField != idx.PKField() {
+		return fmt.Errorf("%w: %s of index %s", ErrUnexpectedPartitionKey, kcb.partitionKeyField, idx.Name())
+	}
+	if err := matchKeyType(idx.PKField(), idx.pkDef.AttributeType, kcb.partitionKeyValue); err != nil {
+		return err
+	}
+	if kcb.sortKeyValue == nil {
+		return nil
+	}
+	if idx.SKField() == "" {
+		return fmt.Errorf("%w: index %s has no sort key", ErrUnexpectedSortKey, idx.Name())
+	}
+	if kcb.sortKeyField != idx.SKField() {
+		return fmt.Errorf("%w: %s of index %s", ErrUnexpectedSortKey, kcb.sortKeyField, idx.Name())
+	}
+	skType := idx.skDef.AttributeType
+	if kcb.sortKeyCondition == BeginsWith && skType == types.ScalarAttributeTypeN {
+		return fmt.Errorf("%w: %s on %s key %s", ErrUnsupportedKeyCondition, BeginsWith, skType, idx.SKField())
+	}
+	if err := matchKeyType(idx.SKField(), skType, kcb.sortKeyValue); err != nil {
+		return err
+	}
+	if kcb.sortKeyCondition == Between && kcb.sortKeyBetweenUpper != nil {
+		return matchKeyType(idx.SKField(), skType, kcb.sortKeyBetweenUpper)
+	}
+	return nil
+}
+
+func matchKeyType(field string, typ types.ScalarAttributeType, value any) error {
+	if typ == "" {
+		return nil
+	}
+	av, err := attributevalue.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var ok bool
+	switch av.(type) {
+	case *types.AttributeValueMemberS:
+		ok = typ == types.ScalarAttributeTypeS
+	case *types.AttributeValueMemberN:
+		ok = typ == types.ScalarAttributeTypeN
+	case *types.AttributeValueMemberB:
+		ok = typ == types.ScalarAttributeTypeB
+	}
+	if !ok {
+		return fmt.Errorf("%w: %T for %s key %s", ErrUnexpectedAttributeType, value, typ, field)
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package dynamox
 
 import (
+	"strings"
+
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 )
 
@@ -48,3 +50,41 @@ func (cond conditionOperator) keyCondBuilder(key string, value any, betweenUpper
 	}
 	return
 }
+
+var conditionOperatorNames = [...]string{
+	Equal:            "eq",
+	LessThan:         "lt",
+	LessThanEqual:    "le",
+	GreaterThan:      "gt",
+	GreaterThanEqual: "ge",
+	BeginsWith:       "begins_with",
+	Between:          "between",
+}
+
+func (cond conditionOperator) String() string {
+	if cond < 0 || int(cond) >= len(conditionOperatorNames) {
+		return conditionOperatorNames[Equal]
+	}
+	return conditionOperatorNames[cond]
+}
+
+// accepts the String() form or the operator symbol ("=", "<", "<=", ">", ">=")
+func ParseConditionOperator(s string) (conditionOperator, error) {
+	switch strings.ToLower(s) {
+	case "eq", "=":
+		return Equal, nil
+	case "lt", "<":
+		return LessThan, nil
+	case "le", "<=":
+		return LessThanEqual, nil
+	case "gt", ">":
+		return GreaterThan, nil
+	case "ge", ">=":
+		return GreaterThanEqual, nil
+	case "begins_with", "beginswith":
+		return BeginsWith, nil
+	case "between":
+		return Between, nil
+	}
+	return Equal, ErrUnexpectedConditionOperator
+}
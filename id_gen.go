@@ -0,0 +1,142 @@
+package dynamox
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	mrand "math/rand/v2"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// IDGenerator generates the value of an empty ID or IDSeq.
+//
+// selectable per type (SetIDGenerator, SetIDSeqGenerator, Options) or per field by name:
+//
+//	type Order struct {
+//		OrderId dynamox.ID `dynamodbav:"orderId" dynamoxid:"uuidv7"`
+//	}
+//
+// empty ids are generated and written back to the struct by MarshalMap and Cruder().Create.
+type IDGenerator interface {
+	NewID() string
+}
+
+type IDGeneratorFunc func() string
+
+func (fn IDGeneratorFunc) NewID() string { return fn() }
+
+const idGenTag = "dynamoxid"
+
+var (
+	UUIDv4Generator IDGenerator = IDGeneratorFunc(uuidV4)  // default of ID
+	UUIDv7Generator IDGenerator = IDGeneratorFunc(uuidV7)  // time-ordered UUID
+	ULIDGenerator   IDGenerator = IDGeneratorFunc(ulidStr) // default of IDSeq, monotonic
+	KSUIDGenerator  IDGenerator = IDGeneratorFunc(ksuid)   // 27 chars, second precision
+)
+
+type idGenHolder struct{ IDGenerator }
+
+var (
+	idGenerator    atomic.Pointer[idGenHolder]
+	idSeqGenerator atomic.Pointer[idGenHolder]
+	namedIDGens    sync.Map // string -> IDGenerator
+)
+
+func init() {
+	SetIDGenerator(UUIDv4Generator)
+	SetIDSeqGenerator(ULIDGenerator)
+	RegisterIDGenerator("uuidv4", UUIDv4Generator)
+	RegisterIDGenerator("uuidv7", UUIDv7Generator)
+	RegisterIDGenerator("ulid", ULIDGenerator)
+	RegisterIDGenerator("ksuid", KSUIDGenerator)
+}
+
+// SetIDGenerator sets the global generator of ID, nil restores UUIDv4Generator
+func SetIDGenerator(gen IDGenerator) {
+	if gen == nil {
+		gen = UUIDv4Generator
+	}
+	idGenerator.Store(&idGenHolder{gen})
+}
+
+// SetIDSeqGenerator sets the global generator of IDSeq, nil restores ULIDGenerator
+func SetIDSeqGenerator(gen IDGenerator) {
+	if gen == nil {
+		gen = ULIDGenerator
+	}
+	idSeqGenerator.Store(&idGenHolder{gen})
+}
+
+// RegisterIDGenerator names gen for the `dynamoxid` field tag
+func RegisterIDGenerator(name string, gen IDGenerator) {
+	namedIDGens.Store(name, gen)
+}
+
+func namedIDGenerator(name string) (IDGenerator, error) {
+	if v, ok := namedIDGens.Load(name); ok {
+		return v.(IDGenerator), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownIDGenerator, name)
+}
+
+/////////////////////////////////////////////////////////////////////////////
+
+// seededGenerator yields the same UUIDv4-formatted sequence for the same seed
+type seededGenerator struct {
+	mu  sync.Mutex
+	rnd *mrand.Rand
+}
+
+// NewSeededIDGenerator is a deterministic generator for tests
+func NewSeededIDGenerator(seed uint64) IDGenerator {
+	return &seededGenerator{rnd: mrand.New(mrand.NewPCG(seed, seed))}
+}
+
+func (g *seededGenerator) NewID() string {
+	g.mu.Lock()
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], g.rnd.Uint64())
+	binary.BigEndian.PutUint64(b[8:], g.rnd.Uint64())
+	g.mu.Unlock()
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant
+	return uuid.UUID(b).String()
+}
+
+func uuidV7() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		panic(err)
+	}
+	return id.String()
+}
+
+const (
+	ksuidEpoch    = 1400000000
+	ksuidLength   = 27
+	base62Charset = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+// ksuid is 4 bytes of seconds since the KSUID epoch + 16 random bytes, in base62
+func ksuid() string {
+	var b [20]byte
+	binary.BigEndian.PutUint32(b[:4], uint32(GlobalClock().Now().Unix()-ksuidEpoch))
+	if _, err := rand.Read(b[4:]); err != nil {
+		panic(err)
+	}
+	var (
+		n    = new(big.Int).SetBytes(b[:])
+		base = big.NewInt(62)
+		mod  = new(big.Int)
+		out  = make([]byte, ksuidLength)
+	)
+	for i := ksuidLength - 1; i >= 0; i-- {
+		n.DivMod(n, base, mod)
+		out[i] = base62Charset[mod.Int64()]
+	}
+	return string(out)
+}
@@ -0,0 +1,135 @@
+package dynamox
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// IDSeq is a ULID: 48-bit ms timestamp + 80-bit entropy, sortable by creation time.
+//
+//	lower, upper, err := IDSeqRange(from, to)
+//	NewKeyCondBuilder().WithPK("customerId", "123").WithSK(Between, "sk", lower, upper)
+//	// or with a prefix: prefix.Composite(lower.String()), prefix.Composite(upper.String())
+
+var (
+	idSeqGen = &monotonicULID{entropy: ulid.Monotonic(rand.Reader, 0)}
+	idSeqAt  = NewIDSeqSource(rand.Reader) // apart, not to reset idSeqGen's entropy
+)
+
+// monotonicULID keeps ULIDs strictly increasing across goroutines within the same millisecond,
+// and for the current time even when the wall clock steps back
+type monotonicULID struct {
+	mu      sync.Mutex
+	entropy *ulid.MonotonicEntropy
+	last    uint64 // ms
+}
+
+// next borrows the next millisecond when the entropy of ms is exhausted, for the current time (clamp);
+// an explicit time fails with ErrIDSeqOverflow instead, its timestamp must stay t
+func (g *monotonicULID) next(t time.Time, clamp bool) (ulid.ULID, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ms := ulid.Timestamp(t)
+	if clamp && ms < g.last {
+		ms = g.last
+	}
+	for {
+		id, err := ulid.New(ms, g.entropy)
+		if err == nil {
+			g.last = max(g.last, ms)
+			return id, nil
+		}
+		if !errors.Is(err, ulid.ErrMonotonicOverflow) {
+			return ulid.ULID{}, err
+		}
+		if !clamp {
+			return ulid.ULID{}, fmt.Errorf("%w: %s", ErrIDSeqOverflow, t.Format(time.RFC3339Nano))
+		}
+		ms++ // entropy exhausted within ms, borrow the next one
+	}
+}
+
+// IDSeqSource generates IDSeqs at given times from its own entropy, see NewIDSeqAt
+type IDSeqSource struct{ gen monotonicULID }
+
+func NewIDSeqSource(entropy io.Reader) *IDSeqSource {
+	return &IDSeqSource{gen: monotonicULID{entropy: ulid.Monotonic(entropy, 0)}}
+}
+
+// At returns an IDSeq whose timestamp is t, monotonic within t's millisecond;
+// t must be within the ULID range, from the Unix epoch to year 10889
+func (s *IDSeqSource) At(t time.Time) (IDSeq, error) {
+	if err := checkIDSeqTime(t); err != nil {
+		return "", err
+	}
+	id, err := s.gen.next(t, false)
+	if err != nil {
+		return "", err
+	}
+	return IDSeq(id.String()), nil
+}
+
+// NewIDSeqAt is At of the package's IDSeqSource
+func NewIDSeqAt(t time.Time) (IDSeq, error) { return idSeqAt.At(t) }
+
+// IDSeqLowerBound is the smallest IDSeq of t's millisecond
+func IDSeqLowerBound(t time.Time) (IDSeq, error) {
+	var id ulid.ULID
+	if err := setIDSeqTime(&id, t); err != nil {
+		return "", err
+	}
+	return IDSeq(id.String()), nil
+}
+
+// IDSeqUpperBound is the largest IDSeq of t's millisecond
+func IDSeqUpperBound(t time.Time) (IDSeq, error) {
+	var id ulid.ULID
+	if err := setIDSeqTime(&id, t); err != nil {
+		return "", err
+	}
+	if err := id.SetEntropy([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}); err != nil {
+		return "", err
+	}
+	return IDSeq(id.String()), nil
+}
+
+// IDSeqRange returns inclusive bounds of IDSeqs created within [from, to], for Between
+func IDSeqRange(from, to time.Time) (lower, upper IDSeq, err error) {
+	if lower, err = IDSeqLowerBound(from); err != nil {
+		return "", "", err
+	}
+	if upper, err = IDSeqUpperBound(to); err != nil {
+		return "", "", err
+	}
+	return lower, upper, nil
+}
+
+// checkIDSeqTime rejects times ulid.Timestamp would wrap around, those before 1970 included
+func checkIDSeqTime(t time.Time) error {
+	if t.Before(time.UnixMilli(0)) || t.After(ulid.Time(ulid.MaxTime())) {
+		return fmt.Errorf("%w: %s", ErrIDSeqTimeOutOfRange, t.Format(time.RFC3339Nano))
+	}
+	return nil
+}
+
+func setIDSeqTime(id *ulid.ULID, t time.Time) error {
+	if err := checkIDSeqTime(t); err != nil {
+		return err
+	}
+	return id.SetTime(ulid.Timestamp(t))
+}
+
+// Time extracts the creation time of id, in ms precision
+func (id IDSeq) Time() (time.Time, error) {
+	u, err := ulid.ParseStrict(string(id))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return ulid.Time(u.Time()), nil
+}
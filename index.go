@@ -1,13 +1,12 @@
 package dynamox
 
 import (
-	"strings"
+	"fmt"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
-// todo: proj & attrdef
-
 type indexKind int
 
 const (
@@ -22,16 +21,32 @@ func (k indexKind) String() string {
 	return "lsi"
 }
 
-const indexNameSep = "-"
-
 type Index struct {
-	// {kind}-{pkField}-{skField(if-exist)}
+	// by IndexNamer, default {kind}-{pkField}-{skField(if-exist)}
 	name  string
+	kind  indexKind
 	pkDef types.AttributeDefinition
 	skDef types.AttributeDefinition
+
+	projection types.ProjectionType // empty: ALL
+	nonKeyAttr []string             // INCLUDE
+	throughput *types.ProvisionedThroughput
+	sparse     bool // not every item has the key attributes, see IndexedItem
 }
 
 func NewIndex(kind indexKind, pkDef types.AttributeDefinition, skDefOps ...types.AttributeDefinition) (Index, error) {
+	if pkDef.AttributeName == nil {
+		return Index{}, ErrInvalidAttributeDefinition
+	}
+	var skField string
+	if len(skDefOps) > 0 && skDefOps[0].AttributeName != nil {
+		skField = *skDefOps[0].AttributeName
+	}
+	return NewIndexNamed(GlobalIndexNamer().IndexName(kind, *pkDef.AttributeName, skField), kind, pkDef, skDefOps...)
+}
+
+// NewIndexNamed is NewIndex with a name out of any IndexNamer, e.g. "GSI1" of single-table designs
+func NewIndexNamed(name string, kind indexKind, pkDef types.AttributeDefinition, skDefOps ...types.AttributeDefinition) (Index, error) {
 	var skDef types.AttributeDefinition
 	if len(skDefOps) > 0 {
 		skDef = skDefOps[0]
@@ -47,13 +62,12 @@ func NewIndex(kind indexKind, pkDef types.AttributeDefinition, skDefOps ...types
 	default:
 		return Index{}, ErrUnexpectedIndexKind
 	}
-	src := []string{kind.String(), *pkDef.AttributeName}
-	if skDef.AttributeName != nil {
-		src = append(src, *skDef.AttributeName)
+	if !validIndexName(name) {
+		return Index{}, fmt.Errorf("%w: %q", ErrUnexpectedIndexFormat, name)
 	}
-	name := joinOmitEmpty(indexNameSep, src...)
 	return Index{
 		name:  name,
+		kind:  kind,
 		pkDef: pkDef,
 		skDef: skDef,
 	}, nil
@@ -83,23 +97,15 @@ func MustLSI(pk types.AttributeDefinition, skOps types.AttributeDefinition) Inde
 	return index
 }
 
+// NewByName parses name by GlobalIndexNamer, see IndexesOf for names of any format
 func NewByName(name string, pkTyp types.ScalarAttributeType, skTypOps ...types.ScalarAttributeType) (Index, error) {
-	if name == "" {
-		return Index{}, ErrUnexpectedIndexFormat
-	}
-	var pkField, skField string
-	split := strings.Split(name, indexNameSep)
-	switch len(split) {
-	case 2:
-		pkField = split[1]
-	case 3:
-		pkField = split[1]
-		skField = split[2]
-	default:
-		return Index{}, ErrUnexpectedIndexFormat
+	kind, pkField, skField, err := GlobalIndexNamer().ParseIndexName(name)
+	if err != nil {
+		return Index{}, err
 	}
 	index := Index{
 		name:  name,
+		kind:  kind,
 		pkDef: types.AttributeDefinition{AttributeName: &pkField, AttributeType: pkTyp},
 	}
 	if len(skField) > 0 {
@@ -111,3 +117,128 @@ func NewByName(name string, pkTyp types.ScalarAttributeType, skTypOps ...types.S
 	}
 	return index, nil
 }
+
+// SecondaryIndexes splits indexes into the CreateTableInput members,
+// checking the INCLUDE attributes summed across them
+func SecondaryIndexes(indexes ...Index) ([]types.GlobalSecondaryIndex, []types.LocalSecondaryIndex, error) {
+	var (
+		gsis       []types.GlobalSecondaryIndex
+		lsis       []types.LocalSecondaryIndex
+		nonKeyAttr int
+	)
+	for _, index := range indexes {
+		nonKeyAttr += len(index.nonKeyAttr)
+		if index.Kind() == GSI {
+			gsi, err := index.GlobalSecondaryIndex()
+			if err != nil {
+				return nil, nil, err
+			}
+			gsis = append(gsis, gsi)
+		} else {
+			lsi, err := index.LocalSecondaryIndex()
+			if err != nil {
+				return nil, nil, err
+			}
+			lsis = append(lsis, lsi)
+		}
+	}
+	if nonKeyAttr > IndexNonKeyAttributesLimit {
+		return nil, nil, fmt.Errorf("%w: %d INCLUDE attributes across indexes, limit %d", ErrInvalidIndexProjection, nonKeyAttr, IndexNonKeyAttributesLimit)
+	}
+	return gsis, lsis, nil
+}
+
+// AttributeDefinitions merges the key definitions of the table and indexes,
+// each attribute once, failing on conflicting types
+func AttributeDefinitions(tableKeys []types.AttributeDefinition, indexes ...Index) ([]types.AttributeDefinition, error) {
+	var (
+		defs = make([]types.AttributeDefinition, 0, len(tableKeys)+2*len(indexes))
+		seen = make(map[string]types.ScalarAttributeType, cap(defs))
+	)
+	add := func(def types.AttributeDefinition) error {
+		if def.AttributeName == nil {
+			return ErrInvalidAttributeDefinition
+		}
+		name := *def.AttributeName
+		if typ, ok := seen[name]; ok {
+			if typ != def.AttributeType {
+				return fmt.Errorf("%w: %s is %s and %s", ErrInvalidAttributeDefinition, name, typ, def.AttributeType)
+			}
+			return nil
+		}
+		seen[name] = def.AttributeType
+		defs = append(defs, def)
+		return nil
+	}
+	for _, def := range tableKeys {
+		if err := add(def); err != nil {
+			return nil, err
+		}
+	}
+	for _, index := range indexes {
+		for _, def := range index.KeyAttrDef() {
+			if err := add(def); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return defs, nil
+}
+
+// IndexesOf recovers the indexes of a DescribeTable result, whatever their names;
+// zero throughput of on-demand tables is left unset
+func IndexesOf(desc *types.TableDescription) ([]Index, error) {
+	if desc == nil {
+		return nil, nil
+	}
+	attrTypes := make(map[string]types.ScalarAttributeType, len(desc.AttributeDefinitions))
+	for _, def := range desc.AttributeDefinitions {
+		attrTypes[aws.ToString(def.AttributeName)] = def.AttributeType
+	}
+	indexes := make([]Index, 0, len(desc.GlobalSecondaryIndexes)+len(desc.LocalSecondaryIndexes))
+	for _, v := range desc.GlobalSecondaryIndexes {
+		index, err := indexOf(GSI, aws.ToString(v.IndexName), v.KeySchema, v.Projection, attrTypes)
+		if err != nil {
+			return nil, err
+		}
+		if pt := v.ProvisionedThroughput; pt != nil && aws.ToInt64(pt.ReadCapacityUnits) > 0 {
+			index = index.WithThroughput(aws.ToInt64(pt.ReadCapacityUnits), aws.ToInt64(pt.WriteCapacityUnits))
+		}
+		indexes = append(indexes, index)
+	}
+	for _, v := range desc.LocalSecondaryIndexes {
+		index, err := indexOf(LSI, aws.ToString(v.IndexName), v.KeySchema, v.Projection, attrTypes)
+		if err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+func indexOf(kind indexKind, name string, keySchema []types.KeySchemaElement, proj *types.Projection, attrTypes map[string]types.ScalarAttributeType) (Index, error) {
+	var pkDef, skDef types.AttributeDefinition
+	for _, key := range keySchema {
+		def := types.AttributeDefinition{AttributeName: key.AttributeName, AttributeType: attrTypes[aws.ToString(key.AttributeName)]}
+		if key.KeyType == types.KeyTypeHash {
+			pkDef = def
+		} else {
+			skDef = def
+		}
+	}
+	index, err := NewIndexNamed(name, kind, pkDef, skDef)
+	if err != nil {
+		return Index{}, fmt.Errorf("index %s: %w", name, err)
+	}
+	if proj != nil {
+		switch proj.ProjectionType {
+		case types.ProjectionTypeKeysOnly:
+			index = index.WithKeysOnly()
+		case types.ProjectionTypeInclude:
+			index = index.WithInclude(proj.NonKeyAttributes...)
+		default:
+			index = index.WithProjectionAll()
+		}
+	}
+	return index, nil
+}
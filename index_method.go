@@ -1,8 +1,9 @@
 package dynamox
 
 import (
-	"strings"
+	"fmt"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
@@ -11,10 +12,7 @@ func (i Index) Name() string {
 }
 
 func (i Index) Kind() indexKind {
-	if strings.HasPrefix(i.name, GSI.String()) {
-		return GSI
-	}
-	return LSI
+	return i.kind
 }
 
 func (i Index) PKField() (pkfield string) {
@@ -31,6 +29,10 @@ func (i Index) SKField() (skField string) {
 	return skField
 }
 
+func (i Index) PKDef() types.AttributeDefinition {
+	return i.pkDef
+}
+
 func (i Index) SKDef() types.AttributeDefinition {
 	return i.skDef
 }
@@ -56,3 +58,124 @@ func (i Index) KeyAttrDef() []types.AttributeDefinition {
 	}
 	return attrDefs
 }
+
+// WithProjectionAll projects every attribute, the default
+func (i Index) WithProjectionAll() Index {
+	i.projection, i.nonKeyAttr = types.ProjectionTypeAll, nil
+	return i
+}
+
+// WithKeysOnly projects the table and index keys only
+func (i Index) WithKeysOnly() Index {
+	i.projection, i.nonKeyAttr = types.ProjectionTypeKeysOnly, nil
+	return i
+}
+
+// WithInclude projects the keys and attrs
+func (i Index) WithInclude(attrs ...string) Index {
+	i.projection, i.nonKeyAttr = types.ProjectionTypeInclude, append([]string(nil), attrs...)
+	return i
+}
+
+// Sparse declares that only the items IndexedItem.IndexKey accepts are in the index
+func (i Index) Sparse() Index {
+	i.sparse = true
+	return i
+}
+
+func (i Index) IsSparse() bool {
+	return i.sparse
+}
+
+// WithThroughput sets the provisioned throughput of a GSI, for tables in PROVISIONED billing mode
+func (i Index) WithThroughput(read, write int64) Index {
+	i.throughput = &types.ProvisionedThroughput{
+		ReadCapacityUnits:  &read,
+		WriteCapacityUnits: &write,
+	}
+	return i
+}
+
+func (i Index) ProjectionType() types.ProjectionType {
+	if i.projection == "" {
+		return types.ProjectionTypeAll
+	}
+	return i.projection
+}
+
+func (i Index) NonKeyAttributes() []string {
+	return append([]string(nil), i.nonKeyAttr...)
+}
+
+func (i Index) Projection() *types.Projection {
+	proj := &types.Projection{ProjectionType: i.ProjectionType()}
+	if proj.ProjectionType == types.ProjectionTypeInclude {
+		proj.NonKeyAttributes = i.NonKeyAttributes()
+	}
+	return proj
+}
+
+func (i Index) Throughput() *types.ProvisionedThroughput {
+	return i.throughput
+}
+
+// Validate checks the projection and throughput, see also SecondaryIndexes for the limit per table
+func (i Index) Validate() error {
+	if i.throughput != nil {
+		if i.Kind() == LSI {
+			return fmt.Errorf("%w: LSI %s shares the throughput of the table", ErrInvalidIndexThroughput, i.name)
+		}
+		if aws.ToInt64(i.throughput.ReadCapacityUnits) < 1 || aws.ToInt64(i.throughput.WriteCapacityUnits) < 1 {
+			return fmt.Errorf("%w: %s", ErrInvalidIndexThroughput, i.name)
+		}
+	}
+	if i.ProjectionType() != types.ProjectionTypeInclude {
+		return nil
+	}
+	switch l := len(i.nonKeyAttr); {
+	case l == 0:
+		return fmt.Errorf("%w: INCLUDE of %s without attributes", ErrInvalidIndexProjection, i.name)
+	case l > IndexNonKeyAttributesLimit:
+		return fmt.Errorf("%w: INCLUDE of %s has %d attributes, limit %d", ErrInvalidIndexProjection, i.name, l, IndexNonKeyAttributesLimit)
+	}
+	seen := make(map[string]struct{}, len(i.nonKeyAttr))
+	for _, attr := range i.nonKeyAttr {
+		if _, dup := seen[attr]; dup || attr == "" {
+			return fmt.Errorf("%w: INCLUDE of %s has %q twice or empty", ErrInvalidIndexProjection, i.name, attr)
+		}
+		if attr == i.PKField() || attr == i.SKField() {
+			return fmt.Errorf("%w: INCLUDE of %s has key attribute %q", ErrInvalidIndexProjection, i.name, attr)
+		}
+		seen[attr] = struct{}{}
+	}
+	return nil
+}
+
+func (i Index) GlobalSecondaryIndex() (types.GlobalSecondaryIndex, error) {
+	if i.Kind() != GSI {
+		return types.GlobalSecondaryIndex{}, fmt.Errorf("%w: %s is not GSI", ErrUnexpectedIndexKind, i.name)
+	}
+	if err := i.Validate(); err != nil {
+		return types.GlobalSecondaryIndex{}, err
+	}
+	return types.GlobalSecondaryIndex{
+		IndexName:             aws.String(i.name),
+		KeySchema:             i.KeySchema(),
+		Projection:            i.Projection(),
+		ProvisionedThroughput: i.throughput,
+	}, nil
+}
+
+func (i Index) LocalSecondaryIndex() (types.LocalSecondaryIndex, error) {
+	if i.Kind() != LSI {
+		return types.LocalSecondaryIndex{}, fmt.Errorf("%w: %s is not LSI", ErrUnexpectedIndexKind, i.name)
+	}
+	if err := i.Validate(); err != nil {
+		return types.LocalSecondaryIndex{}, err
+	}
+	return types.LocalSecondaryIndex{
+		IndexName:  aws.String(i.name),
+		KeySchema:  i.KeySchema(),
+		Projection: i.Projection(),
+	}, nil
+}
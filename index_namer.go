@@ -0,0 +1,139 @@
+package dynamox
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// IndexNamer names an index by its kind and key fields, and parses the name back
+type IndexNamer interface {
+	IndexName(kind indexKind, pkField, skField string) string
+	ParseIndexName(name string) (kind indexKind, pkField, skField string, err error)
+}
+
+// SepIndexNamer joins {kind}{sep}{pkField}{sep}{skField} as is, sep is '-' or '_';
+// a field containing sep gives a name ParseIndexName rejects, see EscapedIndexNamer
+type SepIndexNamer byte
+
+// EscapedIndexNamer is SepIndexNamer escaping a sep or '.' inside a field with '.',
+// so that "user-id" round-trips as "gsi-user.-id". opt-in, as it renames such indexes:
+// "gsi-user-id" of SepIndexNamer becomes "gsi-user.-id"
+type EscapedIndexNamer byte
+
+const indexNameEscape = '.'
+
+var DefaultIndexNamer IndexNamer = SepIndexNamer('-')
+
+type indexNamerHolder struct{ IndexNamer }
+
+var indexNamer atomic.Pointer[indexNamerHolder]
+
+func init() { SetIndexNamer(nil) }
+
+// SetIndexNamer sets the namer of NewIndex and NewByName, nil restores DefaultIndexNamer
+func SetIndexNamer(namer IndexNamer) {
+	if namer == nil {
+		namer = DefaultIndexNamer
+	}
+	indexNamer.Store(&indexNamerHolder{namer})
+}
+
+func GlobalIndexNamer() IndexNamer { return indexNamer.Load().IndexNamer }
+
+func (n SepIndexNamer) IndexName(kind indexKind, pkField, skField string) string {
+	return joinIndexName(indexNameSep(byte(n)), false, kind, pkField, skField)
+}
+
+func (n SepIndexNamer) ParseIndexName(name string) (kind indexKind, pkField, skField string, err error) {
+	return parseIndexName(indexNameSep(byte(n)), false, name)
+}
+
+func (n EscapedIndexNamer) IndexName(kind indexKind, pkField, skField string) string {
+	return joinIndexName(indexNameSep(byte(n)), true, kind, pkField, skField)
+}
+
+func (n EscapedIndexNamer) ParseIndexName(name string) (kind indexKind, pkField, skField string, err error) {
+	return parseIndexName(indexNameSep(byte(n)), true, name)
+}
+
+func indexNameSep(sep byte) byte {
+	if sep == '_' {
+		return '_'
+	}
+	return '-'
+}
+
+func joinIndexName(sep byte, escape bool, kind indexKind, pkField, skField string) string {
+	var sb strings.Builder
+	sb.WriteString(kind.String())
+	for _, field := range []string{pkField, skField} {
+		if field == "" {
+			continue
+		}
+		sb.WriteByte(sep)
+		for i := range len(field) {
+			if c := field[i]; escape && (c == sep || c == indexNameEscape) {
+				sb.WriteByte(indexNameEscape)
+			}
+			sb.WriteByte(field[i])
+		}
+	}
+	return sb.String()
+}
+
+func parseIndexName(sep byte, escape bool, name string) (kind indexKind, pkField, skField string, err error) {
+	var (
+		parts []string
+		part  strings.Builder
+	)
+	for i := 0; i < len(name); i++ {
+		switch c := name[i]; {
+		case escape && c == indexNameEscape:
+			if i++; i == len(name) {
+				return 0, "", "", fmt.Errorf("%w: %q ends with escape", ErrUnexpectedIndexFormat, name)
+			}
+			part.WriteByte(name[i])
+		case c == sep:
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteByte(c)
+		}
+	}
+	parts = append(parts, part.String())
+
+	switch {
+	case len(parts) != 2 && len(parts) != 3:
+		return 0, "", "", fmt.Errorf("%w: %q", ErrUnexpectedIndexFormat, name)
+	case parts[0] == GSI.String():
+		kind = GSI
+	case parts[0] == LSI.String():
+		kind = LSI
+	default:
+		return 0, "", "", fmt.Errorf("%w: %q has no kind", ErrUnexpectedIndexFormat, name)
+	}
+	pkField = parts[1]
+	if len(parts) == 3 {
+		skField = parts[2]
+	}
+	if pkField == "" || (len(parts) == 3 && skField == "") {
+		return 0, "", "", fmt.Errorf("%w: %q has an empty field", ErrUnexpectedIndexFormat, name)
+	}
+	return kind, pkField, skField, nil
+}
+
+// validIndexName: 3-255 characters of [a-zA-Z0-9_.-]
+func validIndexName(name string) bool {
+	if len(name) < 3 || len(name) > 255 {
+		return false
+	}
+	for i := range len(name) {
+		switch c := name[i]; {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '_', c == '.', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
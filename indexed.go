@@ -0,0 +1,164 @@
+package dynamox
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// IndexedItem is optionally implemented by KeyedItems sharing overloaded indexes,
+// writing the generic key attributes (GSI1PK, GSI1SK...) of the indexes registered for their table.
+//
+//	dynamox.RegisterIndexes("App", gsi1)
+//
+//	func (o *Order) IndexKey(idx dynamox.Index) (pk, sk any, ok bool) {
+//		if idx.Name() == gsi1.Name() {
+//			return "CUST#" + o.CustomerId, "ORDER#" + o.OrderId, true
+//		}
+//		return nil, nil, false
+//	}
+//
+// ok is the predicate of a sparse index (Index.Sparse): false drops the item out of it,
+// removing the key attributes on Cruder().Update and leaving them out of MarshalMap,
+// but for the ones its own non-zero fields write.
+type IndexedItem interface {
+	// IndexKey returns the key values of idx, ok false leaves the item out of idx
+	IndexKey(idx Index) (pk, sk any, ok bool)
+}
+
+// IndexSourcedItem is optionally implemented by IndexedItems, naming the attributes IndexKey of idx is derived from.
+// Cruder().Update takes a partial item: it writes the key attributes of idx only when all of them are set,
+// and leaves the index attributes of an IndexedItem without IndexKeySources as stored.
+//
+//	func (o *Order) IndexKeySources(idx dynamox.Index) []string {
+//		return []string{"customerId", "orderId"}
+//	}
+type IndexSourcedItem interface {
+	IndexKeySources(idx Index) []string
+}
+
+var tableIndexes sync.Map // table -> []Index
+
+// RegisterIndexes registers the overloaded indexes of table, written for IndexedItems by MarshalMap and Cruder
+func RegisterIndexes(table string, indexes ...Index) {
+	tableIndexes.Store(table, append(RegisteredIndexes(table), indexes...))
+}
+
+func RegisteredIndexes(table string) []Index {
+	if v, ok := tableIndexes.Load(table); ok {
+		return append([]Index(nil), v.([]Index)...)
+	}
+	return nil
+}
+
+func indexedItemOf(item KeyedItem) (IndexedItem, bool) {
+	if indexed, ok := item.(IndexedItem); ok {
+		return indexed, true
+	}
+	indexed, ok := Untag(item).(IndexedItem)
+	return indexed, ok
+}
+
+// IndexAttributes marshals the index key attributes of item, nil unless item is an IndexedItem
+func IndexAttributes(item KeyedItem) (map[string]types.AttributeValue, error) {
+	values, _, err := indexKeyValues(item, false)
+	if err != nil || values == nil {
+		return nil, err
+	}
+	return attributevalue.MarshalMap(values)
+}
+
+// IndexContains reports whether item is written to idx, false for items out of a sparse index
+func IndexContains(idx Index, item KeyedItem) bool {
+	indexed, ok := indexedItemOf(item)
+	if !ok {
+		return !idx.IsSparse()
+	}
+	_, _, ok = indexed.IndexKey(idx)
+	return ok
+}
+
+// indexKeyValues returns the index key values of item by attribute name, validated against the key types,
+// and the key attributes of the sparse indexes item is out of.
+// partial for the item of an update, see IndexSourcedItem; the attributes the fields of item set are never removed
+func indexKeyValues(item KeyedItem, partial bool) (values map[string]any, removes []string, err error) {
+	indexed, ok := indexedItemOf(item)
+	if !ok {
+		return nil, nil, nil
+	}
+	set := updateAttributes(item)
+	indexes := RegisteredIndexes(item.Table())
+	values = make(map[string]any, 2*len(indexes))
+	for _, idx := range indexes {
+		if partial && !indexSourcesSet(item, idx, set) {
+			continue
+		}
+		pk, sk, ok := indexed.IndexKey(idx)
+		if !ok {
+			if idx.IsSparse() {
+				removes = append(removes, idx.PKField(), idx.SKField())
+			}
+			continue
+		}
+		if err = indexKeyValue(values, idx, idx.PKField(), idx.pkDef.AttributeType, pk); err != nil {
+			return nil, nil, err
+		}
+		if idx.SKField() == "" {
+			continue
+		}
+		if err = indexKeyValue(values, idx, idx.SKField(), idx.skDef.AttributeType, sk); err != nil {
+			return nil, nil, err
+		}
+	}
+	removes = slices.DeleteFunc(removes, func(field string) bool {
+		_, indexed := values[field]
+		_, written := set[field]
+		return indexed || written || field == ""
+	})
+	slices.Sort(removes)
+	return values, slices.Compact(removes), nil
+}
+
+// indexSourcesSet reports whether a partial update sets every source attribute of idx
+func indexSourcesSet(item KeyedItem, idx Index, set map[string]struct{}) bool {
+	sourced, ok := item.(IndexSourcedItem)
+	if !ok {
+		if sourced, ok = Untag(item).(IndexSourcedItem); !ok {
+			return false
+		}
+	}
+	for _, attr := range sourced.IndexKeySources(idx) {
+		if _, ok := set[attr]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func indexKeyValue(values map[string]any, idx Index, field string, typ types.ScalarAttributeType, value any) error {
+	if value == nil {
+		return fmt.Errorf("%w: %s of index %s", ErrRequiredKeyAndValue, field, idx.Name())
+	}
+	if err := matchKeyType(field, typ, value); err != nil {
+		return err
+	}
+	values[field] = value
+	return nil
+}
+
+// withIndexAttributes sets the index key values on update, removing the attributes of the sparse indexes
+// the item is out of; the struct fields of the same attributes are omitted by the caller
+func withIndexAttributes(update expression.UpdateBuilder, values map[string]any, removes []string) expression.UpdateBuilder {
+	for _, field := range slices.Sorted(maps.Keys(values)) {
+		update = update.Set(expression.Name(field), expression.Value(values[field]))
+	}
+	for _, field := range removes {
+		update = update.Remove(expression.Name(field))
+	}
+	return update
+}
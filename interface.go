@@ -26,6 +26,13 @@ type KeyedItem interface {
 	SaveSK() error
 }
 
+// ConventionalItem composes its sort key with the Conventions of the Client writing it:
+// Client paths call SaveSKWith in place of SaveSK. Tagged and dynamox-gen items implement it.
+type ConventionalItem interface {
+	KeyedItem
+	SaveSKWith(cv Conventions) error
+}
+
 // read-only
 //
 // use with attributevalue.UnmarshalListOfMaps
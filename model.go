@@ -27,6 +27,16 @@ func (cud *Model) SetDeletedAt() *Model {
 	return cud
 }
 
+func (m *Model) SetUpdatedAtBy(t Time) *Model {
+	m.UpdatedAt = t
+	return m
+}
+
+func (cud *Model) SetDeletedAtBy(t Time) *Model {
+	cud.DeletedAt = t.Int64()
+	return cud
+}
+
 /////////////////////////////////////////////////////////////////////////////
 
 func HasModel(item KeyedItem) bool { return hasEmbeddedStruct(item, (*Model)(nil)) }
@@ -0,0 +1,300 @@
+package dynamox
+
+import (
+	"cmp"
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Options are Client-scoped conventions; NewClientWithOptions starts from the package globals
+// (SetTimestampUnit, SetCompositeKeySep), so the globals are only defaults.
+//
+//	cli := dynamox.NewClientWithOptions(awsCfg, func(o *dynamox.Options) {
+//		o.TimestampUnit = dynamox.Seconds
+//		o.TableName = func(table string) string { return "prod-" + table }
+//		o.ConsistentRead = true
+//	})
+type Options struct {
+	// Model timestamps, TimeDefaultNow and Client.TimeOf
+	TimestampUnit timestampUnit
+	// sort keys of ConventionalItems (Tagged, dynamox-gen) written by the Client, Client.Composite
+	CompositeKeySep string
+
+	Clock          Clock       // nil: the global clock, see SetClock
+	IDGenerator    IDGenerator // empty ID on Cruder().Create, nil: the global one, see SetIDGenerator
+	IDSeqGenerator IDGenerator // empty IDSeq on Cruder().Create, nil: the global one
+
+	// TableName resolves a logical table name (KeyBase.Table, CtxQuery.SetTable) to the physical one
+	// on every query; batch outputs are keyed by the logical names again.
+	TableName func(table string) string
+
+	ConsistentRead bool // default of Cruder reads without the consistent argument
+
+	EntityRegistry *EntityRegistry // decodes the items of Cruder().ReadPartition
+
+	RetryMaxAttempts int                // 0: SDK default
+	Retryer          func() aws.Retryer // nil: SDK default
+
+	SDKOptions []func(*dynamodb.Options)
+}
+
+// WithSDKOptions passes optFns to dynamodb.NewFromConfig
+func WithSDKOptions(optFns ...func(*dynamodb.Options)) func(*Options) {
+	return func(o *Options) { o.SDKOptions = append(o.SDKOptions, optFns...) }
+}
+
+func defaultOptions() Options {
+	cv := GlobalConventions()
+	return Options{TimestampUnit: cv.TimestampUnit, CompositeKeySep: cv.CompositeKeySep}
+}
+
+func (o Options) Conventions() Conventions {
+	return Conventions{TimestampUnit: o.TimestampUnit, CompositeKeySep: o.CompositeKeySep}
+}
+
+func (o Options) sdkOptions() []func(*dynamodb.Options) {
+	optFns := make([]func(*dynamodb.Options), 0, len(o.SDKOptions)+1)
+	if o.RetryMaxAttempts > 0 || o.Retryer != nil {
+		optFns = append(optFns, func(do *dynamodb.Options) {
+			if o.Retryer != nil {
+				do.Retryer = o.Retryer()
+			}
+			if o.RetryMaxAttempts > 0 {
+				do.RetryMaxAttempts = o.RetryMaxAttempts
+			}
+		})
+	}
+	return append(optFns, o.SDKOptions...)
+}
+
+/////////////////////////////////////////////////////////////////////////////
+
+func (c *Client) Options() Options { return c.opts }
+
+// Now is the Client's clock in its timestamp unit
+func (c *Client) Now() Time { return c.now(context.Background()) }
+
+func (c *Client) now(ctx context.Context) Time {
+	return c.opts.Conventions().TimeOf(c.clock(ctx).Now())
+}
+
+func (c *Client) Conventions() Conventions { return c.opts.Conventions() }
+
+// TimeOf converts t into the Client's timestamp unit
+func (c *Client) TimeOf(t time.Time) Time { return c.opts.Conventions().TimeOf(t) }
+
+// Composite is SortKeyPrefix.Composite with the Client's separator
+func (c *Client) Composite(prefix SortKeyPrefix, srcs ...string) string {
+	return c.opts.Conventions().Composite(prefix, srcs...)
+}
+
+// SplitCompositeKey is SplitCompositeKey with the Client's separator
+func (c *Client) SplitCompositeKey(key string) []string {
+	return c.opts.Conventions().SplitCompositeKey(key)
+}
+
+// clock is the context's, the Client's, or the global clock in order
+func (c *Client) clock(ctx context.Context) Clock {
+	if clock, ok := ctxClock(ctx); ok {
+		return clock
+	}
+	if c.opts.Clock != nil {
+		return c.opts.Clock
+	}
+	return GlobalClock()
+}
+
+// MarshalMap fills empty ID, IDSeq and TimeDefaultNow fields of item with the Client's
+// generators and clock, then marshals it with the Client's Conventions
+func (c *Client) MarshalMap(item KeyedItem, skipSaveSK ...bool) (map[string]types.AttributeValue, error) {
+	if err := c.fillDefaults(context.Background(), item); err != nil {
+		return nil, err
+	}
+	return marshalMap(item, c.opts.Conventions())
+}
+
+// MarshalMapOnlyKey is MarshalMapOnlyKey with the Client's Conventions
+func (c *Client) MarshalMapOnlyKey(item KeyedItem, skipSaveSK ...bool) (map[string]types.AttributeValue, error) {
+	return marshalMapOnlyKey(item, c.opts.Conventions(), len(skipSaveSK) > 0 && skipSaveSK[0])
+}
+
+// TableName resolves a logical table name with Options.TableName
+func (c *Client) TableName(table string) string {
+	if c.opts.TableName == nil {
+		return table
+	}
+	return c.opts.TableName(table)
+}
+
+func (c *Client) tableName(table *string) {
+	if c.opts.TableName != nil && table != nil {
+		*table = c.opts.TableName(*table)
+	}
+}
+
+// tableNames resolves the table keys of a batch request into a new map, with the reverse lookup
+// for the table keys of the output
+func tableNames[V any](c *Client, m map[string]V) (map[string]V, map[string]string) {
+	if c.opts.TableName == nil {
+		return m, nil
+	}
+	resolved, logical := make(map[string]V, len(m)), make(map[string]string, len(m))
+	for table, v := range m {
+		physical := c.opts.TableName(table)
+		resolved[physical], logical[physical] = v, table
+	}
+	return resolved, logical
+}
+
+// logicalTables renames the table keys of a batch output back into the logical names of the request
+func logicalTables[V any](m map[string]V, logical map[string]string) map[string]V {
+	if logical == nil || m == nil {
+		return m
+	}
+	renamed := make(map[string]V, len(m))
+	for table, v := range m {
+		if name, ok := logical[table]; ok {
+			table = name
+		}
+		renamed[table] = v
+	}
+	return renamed
+}
+
+// transactWriteTableNames resolves the table names of transaction items, copying what it changes
+// so the items of the query stay logical
+func (c *Client) transactWriteTableNames(items []types.TransactWriteItem) []types.TransactWriteItem {
+	if c.opts.TableName == nil {
+		return items
+	}
+	resolved := make([]types.TransactWriteItem, len(items))
+	for i, item := range items {
+		if v := item.ConditionCheck; v != nil {
+			cp := *v
+			cp.TableName = aws.String(c.TableName(aws.ToString(v.TableName)))
+			item.ConditionCheck = &cp
+		}
+		if v := item.Put; v != nil {
+			cp := *v
+			cp.TableName = aws.String(c.TableName(aws.ToString(v.TableName)))
+			item.Put = &cp
+		}
+		if v := item.Delete; v != nil {
+			cp := *v
+			cp.TableName = aws.String(c.TableName(aws.ToString(v.TableName)))
+			item.Delete = &cp
+		}
+		if v := item.Update; v != nil {
+			cp := *v
+			cp.TableName = aws.String(c.TableName(aws.ToString(v.TableName)))
+			item.Update = &cp
+		}
+		resolved[i] = item
+	}
+	return resolved
+}
+
+func (c *Client) transactGetTableNames(items []types.TransactGetItem) []types.TransactGetItem {
+	if c.opts.TableName == nil {
+		return items
+	}
+	resolved := make([]types.TransactGetItem, len(items))
+	for i, item := range items {
+		if v := item.Get; v != nil {
+			cp := *v
+			cp.TableName = aws.String(c.TableName(aws.ToString(v.TableName)))
+			item.Get = &cp
+		}
+		resolved[i] = item
+	}
+	return resolved
+}
+
+func (c *Client) consistentRead(consistent []bool) bool {
+	if len(consistent) > 0 {
+		return consistent[0]
+	}
+	return c.opts.ConsistentRead
+}
+
+var (
+	idType             = reflect.TypeFor[ID]()
+	idSeqType          = reflect.TypeFor[IDSeq]()
+	timeDefaultNowType = reflect.TypeFor[TimeDefaultNow]()
+)
+
+func (c *Client) fillDefaults(ctx context.Context, item KeyedItem) error {
+	return fillDefaults(item, c.opts.IDGenerator, c.opts.IDSeqGenerator, func() Time { return c.now(ctx) })
+}
+
+type defaultField struct {
+	index []int
+	typ   reflect.Type
+	gen   string // `dynamoxid` tag
+}
+
+var defaultFieldsCache sync.Map // reflect.Type -> []defaultField
+
+// fillDefaults writes generated values into the empty ID, IDSeq and TimeDefaultNow (when now is given)
+// fields of the struct behind item, so the caller sees them. nil generators are the global ones.
+func fillDefaults(item KeyedItem, idGen, idSeqGen IDGenerator, now func() Time) error {
+	rv := reflect.ValueOf(Untag(item))
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	var nowValue *Time
+	for _, df := range defaultFields(rv.Elem().Type()) {
+		field, err := rv.Elem().FieldByIndexErr(df.index)
+		if err != nil || !field.IsZero() { // nil embedded pointer
+			continue
+		}
+		var gen IDGenerator
+		switch {
+		case df.typ == timeDefaultNowType:
+			if now == nil {
+				continue
+			}
+			if nowValue == nil {
+				t := now()
+				nowValue = &t
+			}
+			field.SetInt(nowValue.Int64())
+			continue
+		case df.gen != "":
+			if gen, err = namedIDGenerator(df.gen); err != nil {
+				return err
+			}
+		case df.typ == idType:
+			gen = cmp.Or(idGen, IDGenerator(idGenerator.Load()))
+		default:
+			gen = cmp.Or(idSeqGen, IDGenerator(idSeqGenerator.Load()))
+		}
+		field.SetString(gen.NewID())
+	}
+	return nil
+}
+
+// defaultFields returns ID, IDSeq and TimeDefaultNow fields, including embedded structs
+func defaultFields(typ reflect.Type) []defaultField {
+	if v, ok := defaultFieldsCache.Load(typ); ok {
+		return v.([]defaultField)
+	}
+	var fields []defaultField
+	for _, field := range reflect.VisibleFields(typ) {
+		if !field.IsExported() {
+			continue
+		}
+		switch field.Type {
+		case idType, idSeqType, timeDefaultNowType:
+			fields = append(fields, defaultField{index: field.Index, typ: field.Type, gen: field.Tag.Get(idGenTag)})
+		}
+	}
+	defaultFieldsCache.Store(typ, fields)
+	return fields
+}
@@ -2,13 +2,29 @@ package dynamox
 
 import (
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
+// PaginationKey is a LastEvaluatedKey, exported as a token of PaginationTokenVersion:
+//
+//	{version}{uvarint count} then per attribute sorted by name {uvarint len}{name}{S|N|B}{uvarint len}{value}
+//
+// base64url without padding. tokens of the legacy JSON form (base64url of {"attr":{"S":"..."}}) are still imported.
 type PaginationKey map[string]types.AttributeValue
 
+const (
+	PaginationTokenLegacy  byte = '{' // first byte of the legacy JSON form
+	PaginationTokenV1      byte = 1
+	PaginationTokenVersion      = PaginationTokenV1
+)
+
 var (
 	_ json.Marshaler   = (*PaginationKey)(nil)
 	_ json.Unmarshaler = (*PaginationKey)(nil)
@@ -18,62 +34,35 @@ func (pgk PaginationKey) LastEvaluatedKey() map[string]types.AttributeValue {
 	return pgk
 }
 
+// Export encodes pgk as a token of PaginationTokenVersion, "" for the last page
+func (pgk PaginationKey) Export() (string, error) {
+	if len(pgk) == 0 {
+		return "", nil
+	}
+	b, err := encodeKey(pgk)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Import decodes a token of any version, "" to nil
 func (pgk *PaginationKey) Import(token string) (PaginationKey, error) {
 	if token == "" {
 		return nil, nil
 	}
-	b, err := base64.URLEncoding.DecodeString(token)
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(token, "="))
 	if err != nil {
-		return nil, err
-	}
-	var wrapper map[string]map[string]string
-	if err := json.Unmarshal(b, &wrapper); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", ErrMalformedPaginationToken, err)
 	}
-	out := make(map[string]types.AttributeValue, len(wrapper))
-	for k, m := range wrapper {
-		switch {
-		case m["S"] != "":
-			out[k] = &types.AttributeValueMemberS{Value: m["S"]}
-		case m["N"] != "":
-			out[k] = &types.AttributeValueMemberN{Value: m["N"]}
-		case m["B"] != "":
-			data, err := base64.StdEncoding.DecodeString(m["B"])
-			if err != nil {
-				return nil, err
-			}
-			out[k] = &types.AttributeValueMemberB{Value: data}
-		default:
-			return nil, ErrUnsupportedAttrValueTypeForKey
-		}
-	}
-	return out, nil
+	return decodeKey(b)
 }
 
 func (pgk PaginationKey) MarshalJSON() ([]byte, error) {
-	if len(pgk) == 0 {
-		return json.Marshal("")
-	}
-	wrapper := make(map[string]map[string]string, len(pgk))
-	for k, av := range pgk {
-		m := make(map[string]string, 1)
-		switch v := av.(type) {
-		case *types.AttributeValueMemberS:
-			m["S"] = v.Value
-		case *types.AttributeValueMemberN:
-			m["N"] = v.Value
-		case *types.AttributeValueMemberB:
-			m["B"] = base64.StdEncoding.EncodeToString(v.Value)
-		default:
-			return nil, ErrUnsupportedAttrValueTypeForKey
-		}
-		wrapper[k] = m
-	}
-	b, err := json.Marshal(wrapper)
+	token, err := pgk.Export()
 	if err != nil {
 		return nil, err
 	}
-	token := base64.URLEncoding.EncodeToString(b)
 	return json.Marshal(token)
 }
 
@@ -89,3 +78,158 @@ func (pgk *PaginationKey) UnmarshalJSON(data []byte) error {
 	*pgk = out
 	return nil
 }
+
+// encodeKey encodes pgk in PaginationTokenVersion, attributes sorted by name
+func encodeKey(pgk PaginationKey) ([]byte, error) {
+	b := binary.AppendUvarint([]byte{PaginationTokenVersion}, uint64(len(pgk)))
+	for _, name := range slices.Sorted(maps.Keys(pgk)) {
+		if name == "" {
+			return nil, fmt.Errorf("%w: empty attribute name", ErrInvalidPaginationKey)
+		}
+		var (
+			typ   byte
+			value []byte
+		)
+		switch v := pgk[name].(type) {
+		case *types.AttributeValueMemberS:
+			typ, value = 'S', []byte(v.Value)
+		case *types.AttributeValueMemberN:
+			typ, value = 'N', []byte(v.Value)
+		case *types.AttributeValueMemberB:
+			typ, value = 'B', v.Value
+		default:
+			return nil, fmt.Errorf("%w: %s is %T", ErrUnsupportedAttrValueTypeForKey, name, v)
+		}
+		b = binary.AppendUvarint(b, uint64(len(name)))
+		b = append(b, name...)
+		b = append(b, typ)
+		b = binary.AppendUvarint(b, uint64(len(value)))
+		b = append(b, value...)
+	}
+	return b, nil
+}
+
+// decodeKey decodes a key of any version by its first byte
+func decodeKey(b []byte) (PaginationKey, error) {
+	if len(b) == 0 {
+		return nil, fmt.Errorf("%w: empty", ErrTruncatedPaginationToken)
+	}
+	switch b[0] {
+	case PaginationTokenV1:
+		return decodeKeyV1(b[1:])
+	case PaginationTokenLegacy:
+		return decodeKeyJSON(b)
+	default:
+		if b[0]&tokenSealedFlag != 0 {
+			return nil, fmt.Errorf("%w: sealed token, open with TokenSealer", ErrUnsupportedTokenVersion)
+		}
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedTokenVersion, b[0])
+	}
+}
+
+func decodeKeyV1(b []byte) (PaginationKey, error) {
+	r := tokenReader{b: b}
+	count, err := r.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if count > uint64(len(r.b))/3 { // 3 bytes at least per attribute
+		return nil, fmt.Errorf("%w: %d attributes", ErrTruncatedPaginationToken, count)
+	}
+	out := make(PaginationKey, count)
+	for range count {
+		name, err := r.bytes()
+		if err != nil {
+			return nil, err
+		}
+		typ, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		value, err := r.bytes()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case len(name) == 0:
+			return nil, fmt.Errorf("%w: empty attribute name", ErrInvalidPaginationKey)
+		case out[string(name)] != nil:
+			return nil, fmt.Errorf("%w: %s twice", ErrInvalidPaginationKey, name)
+		}
+		switch typ {
+		case 'S':
+			out[string(name)] = &types.AttributeValueMemberS{Value: string(value)}
+		case 'N':
+			out[string(name)] = &types.AttributeValueMemberN{Value: string(value)}
+		case 'B':
+			out[string(name)] = &types.AttributeValueMemberB{Value: slices.Clone(value)}
+		default:
+			return nil, fmt.Errorf("%w: %s of type %q", ErrUnsupportedAttrValueTypeForKey, name, typ)
+		}
+	}
+	if len(r.b) > 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrInvalidPaginationKey, len(r.b))
+	}
+	return out, nil
+}
+
+type tokenReader struct{ b []byte }
+
+func (r *tokenReader) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(r.b)
+	if n <= 0 {
+		return 0, fmt.Errorf("%w: length", ErrTruncatedPaginationToken)
+	}
+	r.b = r.b[n:]
+	return v, nil
+}
+
+func (r *tokenReader) byte() (byte, error) {
+	if len(r.b) == 0 {
+		return 0, fmt.Errorf("%w: type", ErrTruncatedPaginationToken)
+	}
+	c := r.b[0]
+	r.b = r.b[1:]
+	return c, nil
+}
+
+func (r *tokenReader) bytes() ([]byte, error) {
+	l, err := r.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if l > uint64(len(r.b)) {
+		return nil, fmt.Errorf("%w: %d bytes of %d", ErrTruncatedPaginationToken, l, len(r.b))
+	}
+	v := r.b[:l]
+	r.b = r.b[l:]
+	return v, nil
+}
+
+// decodeKeyJSON decodes the legacy form {"attr":{"S":"..."}}, B base64 encoded
+func decodeKeyJSON(b []byte) (PaginationKey, error) {
+	var wrapper map[string]map[string]string
+	if err := json.Unmarshal(b, &wrapper); err != nil {
+		return nil, fmt.Errorf("%w: legacy %w", ErrMalformedPaginationToken, err)
+	}
+	out := make(PaginationKey, len(wrapper))
+	for k, m := range wrapper {
+		if len(m) != 1 {
+			return nil, fmt.Errorf("%w: %s has %d types", ErrInvalidPaginationKey, k, len(m))
+		}
+		if s, ok := m["S"]; ok {
+			out[k] = &types.AttributeValueMemberS{Value: s}
+		} else if n, ok := m["N"]; ok {
+			out[k] = &types.AttributeValueMemberN{Value: n}
+		} else if b, ok := m["B"]; ok {
+			data, err := base64.StdEncoding.DecodeString(b)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s %w", ErrMalformedPaginationToken, k, err)
+			}
+			out[k] = &types.AttributeValueMemberB{Value: data}
+		} else {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedAttrValueTypeForKey, k)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,291 @@
+package dynamox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"slices"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// TokenMode is how a TokenSealer protects the pagination tokens handed to API clients
+type TokenMode byte
+
+const (
+	TokenSigned    TokenMode = iota + 1 // HMAC-SHA256, the key stays readable
+	TokenEncrypted                      // AES-GCM, the key is opaque
+)
+
+const (
+	tokenSecretMinLen = 16
+	tokenSealedFlag   = 0x80 // on the first byte, apart from the PaginationKey versions
+)
+
+// TokenKeyRing holds the secrets of pagination tokens by id; tokens are sealed by the active one
+// and opened by any, so that a rotated key keeps opening the tokens in flight until retired.
+//
+//	ring, _ := dynamox.NewTokenKeyRing("2024-05", secret)
+//	ring.Rotate("2024-06", next) // seals with 2024-06, opens both
+//	ring.Retire("2024-05")
+type TokenKeyRing struct {
+	mu     sync.RWMutex
+	active string
+	keys   map[string]tokenKey
+}
+
+type tokenKey struct {
+	mac []byte // HMAC-SHA256 key
+	enc []byte // AES-256 key
+}
+
+func NewTokenKeyRing(id string, secret []byte) (*TokenKeyRing, error) {
+	r := &TokenKeyRing{keys: make(map[string]tokenKey)}
+	if err := r.Rotate(id, secret); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Rotate adds the secret as id and seals with it from now on
+func (r *TokenKeyRing) Rotate(id string, secret []byte) error {
+	if len(id) == 0 || len(id) > 255 {
+		return fmt.Errorf("%w: id of 1-255 bytes", ErrInvalidTokenKey)
+	}
+	if len(secret) < tokenSecretMinLen {
+		return fmt.Errorf("%w: secret %s shorter than %d bytes", ErrInvalidTokenKey, id, tokenSecretMinLen)
+	}
+	key := tokenKey{mac: deriveTokenKey(secret, "mac"), enc: deriveTokenKey(secret, "enc")}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[id], r.active = key, id
+	return nil
+}
+
+// Retire drops id, its tokens are rejected with ErrUnknownTokenKey; the active key can't be retired
+func (r *TokenKeyRing) Retire(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id == r.active {
+		return fmt.Errorf("%w: %s is active", ErrInvalidTokenKey, id)
+	}
+	delete(r.keys, id)
+	return nil
+}
+
+func (r *TokenKeyRing) Active() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.active
+}
+
+func (r *TokenKeyRing) key(id string) (tokenKey, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	key, ok := r.keys[id]
+	return key, ok
+}
+
+// deriveTokenKey separates the keys of signing and encryption out of one secret
+func deriveTokenKey(secret []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("dynamox/pagination-token/" + purpose))
+	return mac.Sum(nil)
+}
+
+// TokenSealer seals PaginationKeys into tokens bound to the query they continue:
+// a token replayed against another table, index or key condition fails to open.
+//
+//	sealer := dynamox.NewTokenSealer(ring, dynamox.TokenEncrypted)
+//	_, lastKey, _ := cli.Query(query, &items)
+//	next, _ := sealer.SealNext(query, lastKey)
+//	// next request
+//	query.SetStartToken(next, sealer)
+type TokenSealer struct {
+	ring *TokenKeyRing
+	mode TokenMode
+}
+
+func NewTokenSealer(ring *TokenKeyRing, mode TokenMode) *TokenSealer {
+	return &TokenSealer{ring: ring, mode: mode}
+}
+
+// SealNext seals the LastEvaluatedKey of query, "" when it was the last page
+func (s *TokenSealer) SealNext(query *CtxQuery, lastKey PaginationKey) (string, error) {
+	if len(lastKey) == 0 {
+		return "", nil
+	}
+	fingerprint, err := query.fingerprint()
+	if err != nil {
+		return "", err
+	}
+	return s.Seal(lastKey, fingerprint)
+}
+
+// Seal protects key by the active key of the ring, fingerprint is authenticated but not embedded:
+// {0x80|mode}{len(id)}{id}{key}{mac} signed, {0x80|mode}{len(id)}{id}{nonce}{sealed key} encrypted,
+// the key encoded in PaginationTokenVersion
+func (s *TokenSealer) Seal(key PaginationKey, fingerprint []byte) (string, error) {
+	payload, err := encodeKey(key)
+	if err != nil {
+		return "", err
+	}
+	id := s.ring.Active()
+	secret, ok := s.ring.key(id)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownTokenKey, id)
+	}
+	header := append([]byte{tokenSealedFlag | byte(s.mode), byte(len(id))}, id...)
+	switch s.mode {
+	case TokenSigned:
+		token := append(header, payload...)
+		token = append(token, tokenMAC(secret.mac, header, fingerprint, payload)...)
+		return base64.RawURLEncoding.EncodeToString(token), nil
+	case TokenEncrypted:
+		aead, err := tokenAEAD(secret.enc)
+		if err != nil {
+			return "", err
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if _, err = rand.Read(nonce); err != nil {
+			return "", err
+		}
+		token := append(header, nonce...)
+		token = aead.Seal(token, nonce, payload, append(header[:len(header):len(header)], fingerprint...))
+		return base64.RawURLEncoding.EncodeToString(token), nil
+	default:
+		return "", fmt.Errorf("%w: mode %d", ErrInvalidTokenKey, s.mode)
+	}
+}
+
+// Open verifies token against fingerprint and returns its key, "" opens to nil
+func (s *TokenSealer) Open(token string, fingerprint []byte) (PaginationKey, error) {
+	if token == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedPaginationToken, err)
+	}
+	if len(b) < 2 || len(b) < 2+int(b[1]) {
+		return nil, fmt.Errorf("%w: short header", ErrMalformedPaginationToken)
+	}
+	if b[0] != tokenSealedFlag|byte(s.mode) {
+		return nil, fmt.Errorf("%w: mode %d, expected %d", ErrMalformedPaginationToken, b[0]&^tokenSealedFlag, s.mode)
+	}
+	header, body := b[:2+int(b[1])], b[2+int(b[1]):]
+	id := string(header[2:])
+	secret, ok := s.ring.key(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTokenKey, id)
+	}
+
+	var payload []byte
+	switch s.mode {
+	case TokenSigned:
+		if len(body) < sha256.Size {
+			return nil, fmt.Errorf("%w: short signature", ErrMalformedPaginationToken)
+		}
+		payload, body = body[:len(body)-sha256.Size], body[len(body)-sha256.Size:]
+		if !hmac.Equal(body, tokenMAC(secret.mac, header, fingerprint, payload)) {
+			return nil, ErrForgedPaginationToken
+		}
+	case TokenEncrypted:
+		aead, err := tokenAEAD(secret.enc)
+		if err != nil {
+			return nil, err
+		}
+		if len(body) < aead.NonceSize() {
+			return nil, fmt.Errorf("%w: short nonce", ErrMalformedPaginationToken)
+		}
+		nonce, ciphertext := body[:aead.NonceSize()], body[aead.NonceSize():]
+		if payload, err = aead.Open(nil, nonce, ciphertext, append(header[:len(header):len(header)], fingerprint...)); err != nil {
+			return nil, ErrForgedPaginationToken
+		}
+	}
+	return decodeKey(payload)
+}
+
+func tokenMAC(key, header, fingerprint, payload []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(header)
+	mac.Write(fingerprint)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func tokenAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+var exprHolder = regexp.MustCompile(`[#:][A-Za-z0-9_]+`)
+
+// fingerprint digests the table, index and key condition of the query, with the names and values
+// the key condition refers to; filters, limit and order don't bind the token
+func (cq CtxQuery) fingerprint() ([]byte, error) {
+	if cq.keyCondBuilder != nil {
+		expr, err := cq.keyCondBuilder.Build()
+		if err != nil {
+			return nil, err
+		}
+		cq.ExprQuery(expr)
+		if idx, ok := cq.keyCondBuilder.Index(); ok {
+			cq.SetIndexOf(idx)
+		}
+	}
+	h := sha256.New()
+	writeField := func(s string) { fmt.Fprintf(h, "%d:%s", len(s), s) }
+	writeField(cq.tableName)
+	if cq.index != nil {
+		writeField(*cq.index)
+	} else {
+		writeField("")
+	}
+	if cq.keyCondExpr == nil {
+		return h.Sum(nil), nil
+	}
+	writeField(*cq.keyCondExpr)
+	holders := exprHolder.FindAllString(*cq.keyCondExpr, -1)
+	slices.Sort(holders)
+	for _, holder := range slices.Compact(holders) {
+		writeField(holder)
+		if holder[0] == '#' {
+			writeField(cq.exprAttrNames[holder])
+			continue
+		}
+		switch v := cq.exprAttrValues[holder].(type) {
+		case *types.AttributeValueMemberS:
+			writeField("S" + v.Value)
+		case *types.AttributeValueMemberN:
+			writeField("N" + v.Value)
+		case *types.AttributeValueMemberB:
+			writeField("B" + string(v.Value))
+		default:
+			return nil, fmt.Errorf("%w: %s is %T", ErrUnsupportedAttrValueTypeForKey, holder, v)
+		}
+	}
+	return h.Sum(nil), nil
+}
+
+// openStartToken opens the token of SetStartToken into the ExclusiveStartKey
+func (cq *CtxQuery) openStartToken() error {
+	if cq.tokenSealer == nil {
+		return nil
+	}
+	fingerprint, err := cq.fingerprint()
+	if err != nil {
+		return err
+	}
+	cq.startKey, err = cq.tokenSealer.Open(cq.startToken, fingerprint)
+	return err
+}
@@ -0,0 +1,102 @@
+package dynamox
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// projectionHolderPrefix differs from the "#0" placeholders of the expression package
+const projectionHolderPrefix = "#proj"
+
+var projectionCache sync.Map // reflect.Type -> []string
+
+// ProjectionOf returns the top-level attribute names of the struct type of v, with key attributes.
+// v is a struct, a pointer to struct, or a (pointer to) slice of them;
+// embedded structs without a dynamodbav name are flattened as the attributevalue encoder does.
+func ProjectionOf(v any) ([]string, error) {
+	typ := reflect.TypeOf(v)
+	for typ != nil && (typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T", ErrUnderivableProjection, v)
+	}
+	if cached, ok := projectionCache.Load(typ); ok {
+		return cached.([]string), nil
+	}
+
+	seen := make(map[string]struct{})
+	attrs := appendProjectionAttrs(nil, typ, seen)
+	if item := newEntityOf(typ); item != nil {
+		attrs = appendAttrs(attrs, seen, item.PKField(), item.SKField())
+	}
+	if len(attrs) == 0 {
+		return nil, fmt.Errorf("%w: %s has no attribute", ErrUnderivableProjection, typ)
+	}
+	projectionCache.Store(typ, attrs)
+	return attrs, nil
+}
+
+func appendProjectionAttrs(attrs []string, typ reflect.Type, seen map[string]struct{}) []string {
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		tag := field.Tag.Get("dynamodbav")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			if embedded := indirectType(field.Type); embedded.Kind() == reflect.Struct {
+				attrs = appendProjectionAttrs(attrs, embedded, seen)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		attrs = appendAttrs(attrs, seen, cmp.Or(name, field.Name))
+	}
+	return attrs
+}
+
+func appendAttrs(attrs []string, seen map[string]struct{}, names ...string) []string {
+	for _, name := range names {
+		if _, ok := seen[name]; ok || name == "" {
+			continue
+		}
+		seen[name] = struct{}{}
+		attrs = append(attrs, name)
+	}
+	return attrs
+}
+
+// projectionExpr merges attrs and keyNames into names under their own placeholders
+func projectionExpr(attrs []string, names map[string]string, keyNames ...string) (*string, map[string]string) {
+	attrs = slices.Clone(attrs)
+	for _, key := range keyNames {
+		if key != "" && !slices.Contains(attrs, key) {
+			attrs = append(attrs, key)
+		}
+	}
+	merged := make(map[string]string, len(names)+len(attrs))
+	maps.Copy(merged, names)
+	holders := make([]string, len(attrs))
+	for i, attr := range attrs {
+		holders[i] = projectionHolderPrefix + strconv.Itoa(i)
+		merged[holders[i]] = attr
+	}
+	return aws.String(strings.Join(holders, ", ")), merged
+}
+
+func keyNamesOf(key map[string]types.AttributeValue) []string {
+	return slices.Sorted(maps.Keys(key))
+}
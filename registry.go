@@ -0,0 +1,190 @@
+package dynamox
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// EntityRegistry maps item shapes of a single-table partition to KeyedItem types,
+// by SortKeyPrefix (longest prefix wins) or by a discriminator attribute.
+//
+//	registry := NewEntityRegistry().
+//		Register((*Profile)(nil), "CUST").
+//		Register((*Bookmark)(nil), "http")
+type EntityRegistry struct {
+	mu       sync.RWMutex
+	prefixes []prefixEntity // sorted by prefix length desc
+	attrs    map[string]map[string]reflect.Type
+	attrKeys []string // registration order of discriminator attributes
+}
+
+type prefixEntity struct {
+	skField string
+	prefix  string
+	typ     reflect.Type
+}
+
+func NewEntityRegistry() *EntityRegistry {
+	return &EntityRegistry{attrs: make(map[string]map[string]reflect.Type)}
+}
+
+// Register maps sort keys beginning with prefix to entity's type, entity may be a typed nil pointer
+func (r *EntityRegistry) Register(entity KeyedItem, prefix SortKeyPrefix) *EntityRegistry {
+	proto, typ := mustEntityProto(entity)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prefixes = append(r.prefixes, prefixEntity{skField: proto.SKField(), prefix: prefix.String(), typ: typ})
+	sort.SliceStable(r.prefixes, func(i, j int) bool { return len(r.prefixes[i].prefix) > len(r.prefixes[j].prefix) })
+	return r
+}
+
+// RegisterByIndex maps items whose sort key of idx begins with prefix to entity's type,
+// for overloaded indexes whose table sort keys don't tell the entities apart
+func (r *EntityRegistry) RegisterByIndex(entity KeyedItem, idx Index, prefix SortKeyPrefix) *EntityRegistry {
+	_, typ := mustEntityProto(entity)
+	field := idx.SKField()
+	if field == "" {
+		field = idx.PKField()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prefixes = append(r.prefixes, prefixEntity{skField: field, prefix: prefix.String(), typ: typ})
+	sort.SliceStable(r.prefixes, func(i, j int) bool { return len(r.prefixes[i].prefix) > len(r.prefixes[j].prefix) })
+	return r
+}
+
+// RegisterByAttr maps items whose attribute attr is the string value to entity's type
+func (r *EntityRegistry) RegisterByAttr(entity KeyedItem, attr, value string) *EntityRegistry {
+	_, typ := mustEntityProto(entity)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exist := r.attrs[attr]; !exist {
+		r.attrs[attr] = make(map[string]reflect.Type)
+		r.attrKeys = append(r.attrKeys, attr)
+	}
+	r.attrs[attr][value] = typ
+	return r
+}
+
+func mustEntityProto(entity KeyedItem) (KeyedItem, reflect.Type) {
+	proto, ok := newKeyedItemOf(entity)
+	if !ok {
+		panic(fmt.Sprintf("dynamox: %T must be a pointer to struct implementing KeyedItem", entity))
+	}
+	return proto, entityTypeOf(entity)
+}
+
+// Resolve returns a new zero item of the type registered for m
+func (r *EntityRegistry) Resolve(m map[string]types.AttributeValue) (KeyedItem, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, attr := range r.attrKeys {
+		if s, ok := m[attr].(*types.AttributeValueMemberS); ok {
+			if typ, exist := r.attrs[attr][s.Value]; exist {
+				return newEntityOf(typ), nil
+			}
+		}
+	}
+	for _, v := range r.prefixes {
+		if s, ok := m[v.skField].(*types.AttributeValueMemberS); ok && strings.HasPrefix(s.Value, v.prefix) {
+			return newEntityOf(v.typ), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnexpectedPartitionItem, describeItemKey(m, r.skFields()))
+}
+
+// proto returns a new zero item of a registered type, whose key fields are shared by the table
+func (r *EntityRegistry) proto() (KeyedItem, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.prefixes) > 0 {
+		return newEntityOf(r.prefixes[0].typ), true
+	}
+	for _, attr := range r.attrKeys {
+		for _, typ := range r.attrs[attr] {
+			return newEntityOf(typ), true
+		}
+	}
+	return nil, false
+}
+
+func (r *EntityRegistry) skFields() []string {
+	var fields []string
+	for _, v := range r.prefixes {
+		if !slices.Contains(fields, v.skField) {
+			fields = append(fields, v.skField)
+		}
+	}
+	return append(fields, r.attrKeys...)
+}
+
+// Decode unmarshals m into its registered type, running PostUnmarshal
+func (r *EntityRegistry) Decode(m map[string]types.AttributeValue) (KeyedItem, error) {
+	item, err := r.Resolve(m)
+	if err != nil {
+		return nil, err
+	}
+	if err = UnmarshalMap(m, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (r *EntityRegistry) DecodeList(l []map[string]types.AttributeValue) ([]KeyedItem, error) {
+	items := make([]KeyedItem, 0, len(l))
+	for _, m := range l {
+		item, err := r.Decode(m)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// unmarshalList appends decoded items to out, which must be *[]KeyedItem
+func (r *EntityRegistry) unmarshalList(l []map[string]types.AttributeValue, out any) error {
+	slice, ok := out.(*[]KeyedItem)
+	if !ok {
+		return fmt.Errorf("%w: %T with EntityRegistry, expected *[]KeyedItem", ErrOutMustBePointerToSlice, out)
+	}
+	items, err := r.DecodeList(l)
+	if err != nil {
+		return err
+	}
+	*slice = append(*slice, items...)
+	return nil
+}
+
+// unmarshal decodes into out, which must be *KeyedItem
+func (r *EntityRegistry) unmarshal(m map[string]types.AttributeValue, out any) error {
+	ptr, ok := out.(*KeyedItem)
+	if !ok {
+		return fmt.Errorf("%w: %T with EntityRegistry, expected *KeyedItem", ErrUnexpectedPartitionItem, out)
+	}
+	item, err := r.Decode(m)
+	if err != nil {
+		return err
+	}
+	*ptr = item
+	return nil
+}
+
+func describeItemKey(m map[string]types.AttributeValue, fields []string) string {
+	parts := make([]string, 0, len(fields))
+	for _, f := range fields {
+		switch v := m[f].(type) {
+		case *types.AttributeValueMemberS:
+			parts = append(parts, f+"="+v.Value)
+		case *types.AttributeValueMemberN:
+			parts = append(parts, f+"="+v.Value)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
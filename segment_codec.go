@@ -0,0 +1,272 @@
+package dynamox
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// order-preserving segments, so that string comparison of composite keys
+// follows the numeric / chronological order of the values:
+//
+//	profileSortKeyPrefix.Composite(SortableInt(-5), ReverseTime(time.Now()))
+//	NewCompositeKeySchema("SCORE", Seg("score", SortableIntSegment), Seg("at", SortableTimeSegment))
+
+const sortableTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+var (
+	SortableIntSegment   SegmentCodec = sortableIntSegment{}
+	SortableFloatSegment SegmentCodec = sortableFloatSegment{}
+	SortableTimeSegment  SegmentCodec = timeSegment{encode: SortableTime, decode: ParseSortableTime}
+	ReverseTimeSegment   SegmentCodec = timeSegment{encode: ReverseTime, decode: ParseReverseTime, check: checkReverseTime}
+)
+
+// PadInt zero-pads v to width digits; values wider than width break the order
+func PadInt(v uint64, width int) string {
+	return fmt.Sprintf("%0*d", width, v)
+}
+
+// SortableInt is v with the sign bit flipped, as 16 hex digits
+func SortableInt(v int64) string {
+	return fmt.Sprintf("%016x", uint64(v)^(1<<63))
+}
+
+func ParseSortableInt(s string) (int64, error) {
+	u, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(u ^ (1 << 63)), nil
+}
+
+// SortableFloat is the IEEE 754 bits of v, sign-flipped for positives and inverted for negatives;
+// -0 is encoded as 0
+func SortableFloat(v float64) string {
+	if v == 0 {
+		v = 0 // drops the sign of -0
+	}
+	bits := math.Float64bits(v)
+	if bits&(1<<63) != 0 {
+		bits = ^bits
+	} else {
+		bits ^= 1 << 63
+	}
+	return fmt.Sprintf("%016x", bits)
+}
+
+func ParseSortableFloat(s string) (float64, error) {
+	bits, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, err
+	}
+	if bits&(1<<63) != 0 {
+		bits ^= 1 << 63
+	} else {
+		bits = ^bits
+	}
+	return math.Float64frombits(bits), nil
+}
+
+// SortableTime is t in UTC with fixed-width nanoseconds
+func SortableTime(t time.Time) string {
+	return t.UTC().Format(sortableTimeLayout)
+}
+
+func ParseSortableTime(s string) (time.Time, error) {
+	return time.Parse(sortableTimeLayout, s)
+}
+
+// ReverseTime sorts newest first, for begins_with queries of the latest items;
+// times before the Unix epoch, the zero time.Time included, are clamped to it, see ReverseTimeSegment
+func ReverseTime(t time.Time) string {
+	if t.Before(reverseTimeMin) {
+		t = reverseTimeMin
+	}
+	return fmt.Sprintf("%019d", math.MaxInt64-t.UnixNano())
+}
+
+// the range of ReverseTime, where UnixNano neither overflows nor goes negative
+var reverseTimeMin, reverseTimeMax = time.Unix(0, 0), time.Unix(0, math.MaxInt64)
+
+func checkReverseTime(t time.Time) error {
+	if t.Before(reverseTimeMin) || t.After(reverseTimeMax) {
+		return fmt.Errorf("%s out of ReverseTime range", t.Format(time.RFC3339Nano))
+	}
+	return nil
+}
+
+func ParseReverseTime(s string) (time.Time, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, math.MaxInt64-n), nil
+}
+
+/////////////////////////////////////////////////////////////////////////////
+
+type padIntSegment int
+
+// PadIntSegment zero-pads non-negative integers to width digits
+func PadIntSegment(width int) SegmentCodec { return padIntSegment(width) }
+
+func (w padIntSegment) EncodeSegment(v reflect.Value) (string, error) {
+	var u uint64
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() < 0 {
+			return "", fmt.Errorf("negative %d, use SortableIntSegment", v.Int())
+		}
+		u = uint64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u = v.Uint()
+	default:
+		return "", fmt.Errorf("unsupported segment type %s", typeName(v))
+	}
+	s := PadInt(u, int(w))
+	if len(s) > int(w) {
+		return "", fmt.Errorf("%d exceeds width %d", u, w)
+	}
+	return s, nil
+}
+
+func (padIntSegment) DecodeSegment(s string, v reflect.Value) error {
+	return textSegment{}.DecodeSegment(s, v)
+}
+
+type sortableIntSegment struct{}
+
+func (sortableIntSegment) EncodeSegment(v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return SortableInt(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > math.MaxInt64 {
+			return "", fmt.Errorf("%d overflows int64", v.Uint())
+		}
+		return SortableInt(int64(v.Uint())), nil
+	}
+	return "", fmt.Errorf("unsupported segment type %s", typeName(v))
+}
+
+func (sortableIntSegment) DecodeSegment(s string, v reflect.Value) error {
+	n, err := ParseSortableInt(s)
+	if err != nil {
+		return err
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.OverflowInt(n) {
+			return fmt.Errorf("%d overflows %s", n, v.Type())
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n < 0 || v.OverflowUint(uint64(n)) {
+			return fmt.Errorf("%d overflows %s", n, v.Type())
+		}
+		v.SetUint(uint64(n))
+	default:
+		return fmt.Errorf("unsupported segment type %s", v.Type())
+	}
+	return nil
+}
+
+type sortableFloatSegment struct{}
+
+func (sortableFloatSegment) EncodeSegment(v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return SortableFloat(v.Float()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return SortableFloat(float64(v.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return SortableFloat(float64(v.Uint())), nil
+	}
+	return "", fmt.Errorf("unsupported segment type %s", typeName(v))
+}
+
+func (sortableFloatSegment) DecodeSegment(s string, v reflect.Value) error {
+	f, err := ParseSortableFloat(s)
+	if err != nil {
+		return err
+	}
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(f)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(int64(f))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if f < 0 {
+			return fmt.Errorf("%v overflows %s", f, v.Type())
+		}
+		v.SetUint(uint64(f))
+	default:
+		return fmt.Errorf("unsupported segment type %s", v.Type())
+	}
+	return nil
+}
+
+// timeSegment encodes time.Time and the Time types of this package
+type timeSegment struct {
+	encode func(time.Time) string
+	decode func(string) (time.Time, error)
+	check  func(time.Time) error // rejects times encode would clamp, optional
+}
+
+func (ts timeSegment) EncodeSegment(v reflect.Value) (string, error) {
+	if !v.IsValid() {
+		return "", ErrInsufficientCompositeKeySrc
+	}
+	var t time.Time
+	switch tv := v.Interface().(type) {
+	case time.Time:
+		t = tv
+	case TimeRFC3339:
+		t = tv.Time
+	case interface{ Time() time.Time }:
+		t = tv.Time()
+	default:
+		return "", fmt.Errorf("unsupported segment type %s", v.Type())
+	}
+	if ts.check != nil {
+		if err := ts.check(t); err != nil {
+			return "", err
+		}
+	}
+	return ts.encode(t), nil
+}
+
+func (ts timeSegment) DecodeSegment(s string, v reflect.Value) error {
+	t, err := ts.decode(s)
+	if err != nil {
+		return err
+	}
+	switch v.Addr().Interface().(type) {
+	case *time.Time:
+		v.Set(reflect.ValueOf(t))
+	case *TimeRFC3339:
+		v.Set(reflect.ValueOf(TimeRFC3339Of(t)))
+	case *Time, *TimeDefaultNow:
+		v.SetInt(TimeByTime(t).Int64())
+	case *TimeSec:
+		v.SetInt(int64(TimeSecOf(t)))
+	case *TimeMilli:
+		v.SetInt(int64(TimeMilliOf(t)))
+	case *TimeMicro:
+		v.SetInt(int64(TimeMicroOf(t)))
+	case *TimeNano:
+		v.SetInt(int64(TimeNanoOf(t)))
+	default:
+		return fmt.Errorf("unsupported segment type %s", v.Type())
+	}
+	return nil
+}
+
+func typeName(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+	return v.Type().String()
+}
@@ -0,0 +1,304 @@
+package dynamox
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// struct tag driven KeyedItem, instead of hand-written KeyBase methods
+//
+//	type Profile struct {
+//		CustomerId string `dynamodbav:"customerId" dynamox:"pk"`
+//		Sk         string `dynamodbav:"sk" dynamox:"sk,prefix=CUST,from=CustomerId"`
+//		Email      string `dynamodbav:"email"`
+//	}
+//
+//	dynamox.RegisterTable((*Profile)(nil), "CustomerBookmark")
+//	item := dynamox.MustTagged(&Profile{CustomerId: "123"})
+//	cli.Cruder().Create(ctx, item, true)
+//
+// options of pk/sk:
+//   - prefix=P: SortKeyPrefix of the composite key
+//   - from=A+B: fields composited (after prefix) when the key is empty
+const tagKey = "dynamox"
+
+var (
+	_ KeyedItem                  = (*taggedItem)(nil)
+	_ attributevalue.Marshaler   = (*taggedItem)(nil)
+	_ attributevalue.Unmarshaler = (*taggedItem)(nil)
+	_ KeyBase                    = (*taggedKey)(nil)
+	_ KeyBaseHooker              = (*taggedKey)(nil)
+	_ attributevalue.Marshaler   = (*taggedKey)(nil)
+)
+
+var (
+	taggedTables sync.Map // reflect.Type -> string
+	taggedMetas  sync.Map // reflect.Type -> *taggedMeta
+)
+
+// RegisterTable binds a table name to a tagged struct type, e.g. RegisterTable((*Profile)(nil), "CustomerBookmark")
+func RegisterTable(entity any, table string) {
+	taggedTables.Store(indirectType(reflect.TypeOf(entity)), table)
+}
+
+type taggedKeyMeta struct {
+	index  []int
+	name   string // attribute name
+	prefix SortKeyPrefix
+	from   [][]int
+}
+
+type taggedMeta struct {
+	typ reflect.Type
+	pk  taggedKeyMeta
+	sk  *taggedKeyMeta
+}
+
+func taggedMetaOf(typ reflect.Type) (*taggedMeta, error) {
+	if v, ok := taggedMetas.Load(typ); ok {
+		return v.(*taggedMeta), nil
+	}
+	meta, err := parseTaggedMeta(typ)
+	if err != nil {
+		return nil, err
+	}
+	v, _ := taggedMetas.LoadOrStore(typ, meta)
+	return v.(*taggedMeta), nil
+}
+
+func parseTaggedMeta(typ reflect.Type) (*taggedMeta, error) {
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %s is not struct", ErrInvalidKeyTag, typ)
+	}
+	meta := &taggedMeta{typ: typ}
+	var (
+		pk, sk   *taggedKeyMeta
+		fromRefs = map[*taggedKeyMeta][]string{}
+	)
+	for _, field := range reflect.VisibleFields(typ) {
+		tag, ok := field.Tag.Lookup(tagKey)
+		if !ok || !field.IsExported() {
+			continue
+		}
+		opts := strings.Split(tag, ",")
+		key := &taggedKeyMeta{index: field.Index, name: attrName(field)}
+		for _, opt := range opts[1:] {
+			k, v, _ := strings.Cut(strings.TrimSpace(opt), "=")
+			switch k {
+			case "prefix":
+				key.prefix = SortKeyPrefix(v)
+			case "from":
+				fromRefs[key] = strings.Split(v, "+")
+			default:
+				return nil, fmt.Errorf("%w: %s.%s unknown option %q", ErrInvalidKeyTag, typ, field.Name, k)
+			}
+		}
+		switch strings.TrimSpace(opts[0]) {
+		case "pk":
+			if pk != nil {
+				return nil, fmt.Errorf("%w: %s has multiple pk", ErrInvalidKeyTag, typ)
+			}
+			pk = key
+		case "sk":
+			if sk != nil {
+				return nil, fmt.Errorf("%w: %s has multiple sk", ErrInvalidKeyTag, typ)
+			}
+			sk = key
+		default:
+			return nil, fmt.Errorf("%w: %s.%s unknown key %q", ErrInvalidKeyTag, typ, field.Name, opts[0])
+		}
+	}
+	if pk == nil {
+		return nil, fmt.Errorf("%w: %s has no pk", ErrInvalidKeyTag, typ)
+	}
+	for key, names := range fromRefs {
+		for _, name := range names {
+			field, ok := typ.FieldByName(name)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s has no field %q", ErrInvalidKeyTag, typ, name)
+			}
+			key.from = append(key.from, field.Index)
+		}
+	}
+	meta.pk, meta.sk = *pk, sk
+	return meta, nil
+}
+
+func attrName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("dynamodbav"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+/////////////////////////////////////////////////////////////////////////////
+
+type taggedItem struct {
+	rv   reflect.Value // addressable struct
+	meta *taggedMeta
+}
+
+// Tagged adapts a pointer to a `dynamox` tagged struct into a KeyedItem; metadata is cached per type
+func Tagged(v any) (KeyedItem, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return nil, ErrOutputNilPointer
+	}
+	meta, err := taggedMetaOf(rv.Elem().Type())
+	if err != nil {
+		return nil, err
+	}
+	return &taggedItem{rv: rv.Elem(), meta: meta}, nil
+}
+
+func MustTagged(v any) KeyedItem {
+	item, err := Tagged(v)
+	if err != nil {
+		panic(err)
+	}
+	return item
+}
+
+// Untag returns the pointer to the tagged struct behind a Tagged KeyedItem, or item itself
+func Untag(item KeyedItem) any {
+	if t, ok := item.(*taggedItem); ok {
+		return t.rv.Addr().Interface()
+	}
+	return item
+}
+
+// untagAny is Untag for the reflection helpers taking any
+func untagAny(v any) any {
+	if item, ok := v.(KeyedItem); ok {
+		return Untag(item)
+	}
+	return v
+}
+
+func (t *taggedItem) Table() string {
+	if v, ok := taggedTables.Load(t.meta.typ); ok {
+		return v.(string)
+	}
+	return ""
+}
+
+func (t *taggedItem) PKField() string { return t.meta.pk.name }
+func (t *taggedItem) PK() any         { return t.rv.FieldByIndex(t.meta.pk.index).Interface() }
+
+func (t *taggedItem) SKField() string {
+	if t.meta.sk == nil {
+		return ""
+	}
+	return t.meta.sk.name
+}
+
+func (t *taggedItem) SK() any {
+	if t.meta.sk == nil {
+		return nil
+	}
+	return t.rv.FieldByIndex(t.meta.sk.index).Interface()
+}
+
+func (t *taggedItem) GetKeyBase() KeyBase { return &taggedKey{t} }
+
+func (t *taggedItem) SaveSK() error { return t.SaveSKWith(GlobalConventions()) }
+
+func (t *taggedItem) SaveSKWith(cv Conventions) error {
+	if t.meta.sk == nil {
+		return nil
+	}
+	return t.composite(t.meta.sk, cv)
+}
+
+// composite fills an empty key from prefix/from options
+func (t *taggedItem) composite(key *taggedKeyMeta, cv Conventions) error {
+	field := t.rv.FieldByIndex(key.index)
+	if !field.IsZero() || (key.prefix == "" && len(key.from) == 0) {
+		return nil
+	}
+	if field.Kind() != reflect.String {
+		return fmt.Errorf("%w: composite %s must be string kind", ErrInvalidKeyTag, key.name)
+	}
+	srcs := make([]string, 0, len(key.from))
+	for _, index := range key.from {
+		src := t.rv.FieldByIndex(index)
+		if src.IsZero() {
+			return fmt.Errorf("%w: %s", ErrInsufficientCompositeKeySrc, key.name)
+		}
+		if s, ok := src.Interface().(fmt.Stringer); ok {
+			srcs = append(srcs, s.String())
+		} else {
+			srcs = append(srcs, fmt.Sprint(src.Interface()))
+		}
+	}
+	var value string
+	if key.prefix != "" {
+		value = cv.Composite(key.prefix, srcs...)
+	} else {
+		value = compositeKeyWith(cv.sep(), srcs...)
+	}
+	field.SetString(value)
+	return nil
+}
+
+func (t *taggedItem) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	return attributevalue.Marshal(t.rv.Interface())
+}
+
+func (t *taggedItem) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	return attributevalue.Unmarshal(av, t.rv.Addr().Interface())
+}
+
+func (t *taggedItem) entityType() reflect.Type { return t.meta.typ }
+
+func (t *taggedItem) newEntity() KeyedItem {
+	return &taggedItem{rv: reflect.New(t.meta.typ).Elem(), meta: t.meta}
+}
+
+/////////////////////////////////////////////////////////////////////////////
+
+type taggedKey struct{ *taggedItem }
+
+// PreMarshal fills a composite pk and runs the struct's own hook, if any
+func (k *taggedKey) PreMarshal() error { return k.preMarshalWith(GlobalConventions()) }
+
+func (k *taggedKey) preMarshalWith(cv Conventions) error {
+	if err := k.composite(&k.meta.pk, cv); err != nil {
+		return err
+	}
+	if hooker, ok := k.rv.Addr().Interface().(KeyBaseHooker); ok {
+		return hooker.PreMarshal()
+	}
+	return nil
+}
+
+func (k *taggedKey) PostUnmarshal() error {
+	if hooker, ok := k.rv.Addr().Interface().(KeyBaseHooker); ok {
+		return hooker.PostUnmarshal()
+	}
+	return nil
+}
+
+// only key attributes, for MarshalMapOnlyKey
+func (k *taggedKey) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	m := make(map[string]types.AttributeValue, 2)
+	pk, err := attributevalue.Marshal(k.PK())
+	if err != nil {
+		return nil, err
+	}
+	m[k.PKField()] = pk
+	if k.meta.sk != nil {
+		sk, err := attributevalue.Marshal(k.SK())
+		if err != nil {
+			return nil, err
+		}
+		m[k.SKField()] = sk
+	}
+	return &types.AttributeValueMemberM{Value: m}, nil
+}
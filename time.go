@@ -1,8 +1,8 @@
 package dynamox
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"strconv"
 	"time"
 
@@ -10,23 +10,62 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
+// Time and TimeDefaultNow are epoch timestamps in the unit of SetTimestampUnit;
+// TimeSec, TimeMilli, TimeMicro, TimeNano and TimeRFC3339 fix the unit / format per field.
+//
+//	type Event struct {
+//		At      dynamox.TimeMilli   `dynamodbav:"at"`
+//		Expires dynamox.TimeSec     `dynamodbav:"expires"` // TTL attribute
+//		Shown   dynamox.TimeRFC3339 `dynamodbav:"shown"`
+//	}
 type (
 	TimeDefaultNow int64
 	Time           int64 // omitempty without pointer
+
+	TimeSec   int64
+	TimeMilli int64
+	TimeMicro int64
+	TimeNano  int64
+
+	TimeRFC3339 struct{ time.Time } // S attribute, RFC3339 with nanoseconds
 )
 
-func TimeNow() Time                     { return Time(newTimestampFn()) }
-func TimeByTime(t time.Time) Time       { return Time(t.UnixMilli()) }
-func (dt Time) Int64() int64            { return int64(dt) }
-func (dt Time) Time() time.Time         { return parseTimestampFn(dt.Int64()) }
-func (dt Time) Duration() time.Duration { return time.Duration(dt) }
+func TimeNow() Time                       { return Time(newTimestampFn()) }
+func TimeNowCtx(ctx context.Context) Time { return TimeByTime(ClockFrom(ctx).Now()) }
+func TimeByTime(t time.Time) Time         { return Time(TimestampUnit().fromTime(t)) }
+func (dt Time) Int64() int64              { return int64(dt) }
+func (dt Time) Time() time.Time           { return parseTimestampFn(dt.Int64()) }
+func (dt Time) Duration() time.Duration   { return time.Duration(dt) }
+
+func (dtn TimeDefaultNow) Int64() int64    { return int64(dtn) }
+func (dtn TimeDefaultNow) Time() time.Time { return parseTimestampFn(dtn.Int64()) }
+
+func TimeSecOf(t time.Time) TimeSec         { return TimeSec(t.Unix()) }
+func TimeMilliOf(t time.Time) TimeMilli     { return TimeMilli(t.UnixMilli()) }
+func TimeMicroOf(t time.Time) TimeMicro     { return TimeMicro(t.UnixMicro()) }
+func TimeNanoOf(t time.Time) TimeNano       { return TimeNano(t.UnixNano()) }
+func TimeRFC3339Of(t time.Time) TimeRFC3339 { return TimeRFC3339{t} }
+
+func (ts TimeSec) Time() time.Time   { return Seconds.toTime(int64(ts)) }
+func (ts TimeMilli) Time() time.Time { return MilliSeconds.toTime(int64(ts)) }
+func (ts TimeMicro) Time() time.Time { return MicroSeconds.toTime(int64(ts)) }
+func (ts TimeNano) Time() time.Time  { return NanoSeconds.toTime(int64(ts)) }
 
 var (
 	_ attributevalue.Marshaler   = (*TimeDefaultNow)(nil)
+	_ attributevalue.Unmarshaler = (*TimeDefaultNow)(nil)
+	_ json.Marshaler             = (*TimeDefaultNow)(nil)
+	_ json.Unmarshaler           = (*TimeDefaultNow)(nil)
 	_ json.Marshaler             = (*Time)(nil)
 	_ json.Unmarshaler           = (*Time)(nil)
 	_ attributevalue.Marshaler   = (*Time)(nil)
 	_ attributevalue.Unmarshaler = (*Time)(nil)
+	_ attributevalue.Marshaler   = (*TimeSec)(nil)
+	_ attributevalue.Unmarshaler = (*TimeSec)(nil)
+	_ attributevalue.Marshaler   = (*TimeRFC3339)(nil)
+	_ attributevalue.Unmarshaler = (*TimeRFC3339)(nil)
+	_ json.Marshaler             = (*TimeRFC3339)(nil)
+	_ json.Unmarshaler           = (*TimeRFC3339)(nil)
 )
 
 func (dtn TimeDefaultNow) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
@@ -36,48 +75,140 @@ func (dtn TimeDefaultNow) MarshalDynamoDBAttributeValue() (types.AttributeValue,
 	return &types.AttributeValueMemberN{Value: strconv.FormatInt(int64(dtn), 10)}, nil
 }
 
-func (dt Time) MarshalJSON() ([]byte, error) {
-	if dt == 0 {
-		return []byte("null"), nil
+func (dtn *TimeDefaultNow) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	return unmarshalEpoch(av, (*int64)(dtn))
+}
+
+func (dtn TimeDefaultNow) MarshalJSON() ([]byte, error) { return marshalEpochJSON(int64(dtn)) }
+func (dtn *TimeDefaultNow) UnmarshalJSON(data []byte) error {
+	return unmarshalEpochJSON(data, (*int64)(dtn))
+}
+
+func (dt Time) MarshalJSON() ([]byte, error)     { return marshalEpochJSON(int64(dt)) }
+func (dt *Time) UnmarshalJSON(data []byte) error { return unmarshalEpochJSON(data, (*int64)(dt)) }
+
+func (dt Time) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	return marshalEpoch(int64(dt))
+}
+
+func (dt *Time) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	return unmarshalEpoch(av, (*int64)(dt))
+}
+
+func (ts TimeSec) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	return marshalEpoch(int64(ts))
+}
+func (ts *TimeSec) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	return unmarshalEpoch(av, (*int64)(ts))
+}
+func (ts TimeSec) MarshalJSON() ([]byte, error)     { return marshalEpochJSON(int64(ts)) }
+func (ts *TimeSec) UnmarshalJSON(data []byte) error { return unmarshalEpochJSON(data, (*int64)(ts)) }
+
+func (ts TimeMilli) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	return marshalEpoch(int64(ts))
+}
+func (ts *TimeMilli) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	return unmarshalEpoch(av, (*int64)(ts))
+}
+func (ts TimeMilli) MarshalJSON() ([]byte, error)     { return marshalEpochJSON(int64(ts)) }
+func (ts *TimeMilli) UnmarshalJSON(data []byte) error { return unmarshalEpochJSON(data, (*int64)(ts)) }
+
+func (ts TimeMicro) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	return marshalEpoch(int64(ts))
+}
+func (ts *TimeMicro) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	return unmarshalEpoch(av, (*int64)(ts))
+}
+func (ts TimeMicro) MarshalJSON() ([]byte, error)     { return marshalEpochJSON(int64(ts)) }
+func (ts *TimeMicro) UnmarshalJSON(data []byte) error { return unmarshalEpochJSON(data, (*int64)(ts)) }
+
+func (ts TimeNano) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	return marshalEpoch(int64(ts))
+}
+func (ts *TimeNano) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	return unmarshalEpoch(av, (*int64)(ts))
+}
+func (ts TimeNano) MarshalJSON() ([]byte, error)     { return marshalEpochJSON(int64(ts)) }
+func (ts *TimeNano) UnmarshalJSON(data []byte) error { return unmarshalEpochJSON(data, (*int64)(ts)) }
+
+func (ts TimeRFC3339) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	if ts.IsZero() {
+		return &types.AttributeValueMemberNULL{Value: true}, nil
 	}
-	return []byte(strconv.FormatInt(int64(dt), 10)), nil
+	return &types.AttributeValueMemberS{Value: ts.UTC().Format(time.RFC3339Nano)}, nil
 }
 
-func (dt *Time) UnmarshalJSON(data []byte) error {
-	if len(data) == 0 {
-		*dt = 0
+func (ts *TimeRFC3339) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	switch v := av.(type) {
+	case nil, *types.AttributeValueMemberNULL:
+		ts.Time = time.Time{}
 		return nil
+	case *types.AttributeValueMemberS:
+		t, err := time.Parse(time.RFC3339Nano, v.Value)
+		if err == nil {
+			ts.Time = t
+		}
+		return err
 	}
-	s := string(data)
-	if s == "null" {
-		*dt = 0
-		return nil
+	return ErrExpectedStringAttribute
+}
+
+func (ts TimeRFC3339) MarshalJSON() ([]byte, error) {
+	if ts.IsZero() {
+		return []byte("null"), nil
 	}
-	v, err := strconv.ParseInt(s, 10, 64)
-	if err == nil {
-		*dt = Time(v)
+	return json.Marshal(ts.UTC().Format(time.RFC3339Nano))
+}
+
+func (ts *TimeRFC3339) UnmarshalJSON(data []byte) error {
+	if len(data) == 0 || string(data) == "null" {
+		ts.Time = time.Time{}
+		return nil
 	}
-	return err
+	return ts.Time.UnmarshalJSON(data)
 }
 
-func (dt Time) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
-	if dt == 0 {
+/////////////////////////////////////////////////////////////////////////////
+
+// marshalEpoch leaves zero to the default encoder, as Time always has
+func marshalEpoch(v int64) (types.AttributeValue, error) {
+	if v == 0 {
 		return nil, nil
 	}
-	return &types.AttributeValueMemberN{Value: strconv.FormatInt(int64(dt), 10)}, nil
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(v, 10)}, nil
 }
 
-func (dt *Time) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
-	if av == nil {
+func unmarshalEpoch(av types.AttributeValue, out *int64) error {
+	switch v := av.(type) {
+	case nil, *types.AttributeValueMemberNULL:
+		*out = 0
 		return nil
+	case *types.AttributeValueMemberN:
+		n, err := strconv.ParseInt(v.Value, 10, 64)
+		if err == nil {
+			*out = n
+		}
+		return err
 	}
-	n, ok := av.(*types.AttributeValueMemberN)
-	if !ok {
-		return errors.New("expected N attribute")
+	return ErrExpectedNumberAttribute
+}
+
+func marshalEpochJSON(v int64) ([]byte, error) {
+	if v == 0 {
+		return []byte("null"), nil
 	}
-	v, err := strconv.ParseInt(n.Value, 10, 64)
+	return []byte(strconv.FormatInt(v, 10)), nil
+}
+
+func unmarshalEpochJSON(data []byte, out *int64) error {
+	s := string(data)
+	if len(s) == 0 || s == "null" {
+		*out = 0
+		return nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
 	if err == nil {
-		*dt = Time(v)
+		*out = v
 	}
 	return err
 }
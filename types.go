@@ -15,7 +15,7 @@ var (
 	_ attributevalue.Marshaler = (*IDSeq)(nil)
 )
 
-func NewID() ID              { return ID(uuidV4()) }
+func NewID() ID              { return ID(idGenerator.Load().NewID()) }
 func (id ID) String() string { return string(id) }
 func (id ID) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
 	if len(id) == 0 {
@@ -24,7 +24,7 @@ func (id ID) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
 	return &types.AttributeValueMemberS{Value: string(id)}, nil
 }
 
-func NewIDSeq() IDSeq           { return IDSeq(ulidStr()) }
+func NewIDSeq() IDSeq           { return IDSeq(idSeqGenerator.Load().NewID()) }
 func (id IDSeq) String() string { return string(id) }
 func (id IDSeq) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
 	if len(id) == 0 {
@@ -39,6 +39,7 @@ func (dsp SortKeyPrefix) String() string {
 	return string(dsp)
 }
 
+// Composite joins the prefix and srcs with CompositeKeySep as they are, see CompositeKeySchema.Escaped
 func (dsp SortKeyPrefix) Composite(srcs ...string) string {
 	return compositeKey(append([]string{dsp.String()}, srcs...)...)
 }
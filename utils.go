@@ -5,15 +5,15 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"unicode/utf8"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/google/uuid"
-	"github.com/oklog/ulid/v2"
 )
 
 func KeyedItem2UpdateExpr(item KeyedItem, cond ...expression.ConditionBuilder) (expression.Expression, error) {
-	update, err := structToUpdateBuilderOmitEmpty(item, item.PKField(), item.SKField())
+	update, err := KeyedItem2UpdateBuilder(item)
 	if err != nil {
 		return expression.Expression{}, err
 	}
@@ -28,8 +28,22 @@ func KeyedItem2UpdateExpr(item KeyedItem, cond ...expression.ConditionBuilder) (
 	return updateExpr, nil
 }
 
+// KeyedItem2UpdateBuilder sets the non-zero fields of item, and the index key attributes of an IndexedItem
+// whose sources are all set, see IndexSourcedItem
 func KeyedItem2UpdateBuilder(item KeyedItem) (expression.UpdateBuilder, error) {
-	return structToUpdateBuilderOmitEmpty(item, item.PKField(), item.SKField())
+	values, removes, err := indexKeyValues(item, true)
+	if err != nil {
+		return expression.UpdateBuilder{}, err
+	}
+	omitKeys := append([]string{item.PKField(), item.SKField()}, removes...)
+	for field := range values {
+		omitKeys = append(omitKeys, field)
+	}
+	update, err := structToUpdateBuilderOmitEmpty(item, omitKeys...)
+	if err != nil {
+		return update, err
+	}
+	return withIndexAttributes(update, values, removes), nil
 }
 
 func IsEnableKey(keyval any, av types.AttributeValue) bool {
@@ -72,8 +86,83 @@ func CheckListOfMaps(av types.AttributeValue) error {
 
 /////////////////////////////////////////////////////////////////////////////
 
+// compositeKey joins segments as they are, CompositeKeySchema.Escaped escapes them
 func compositeKey(srcs ...string) string {
-	return strings.Join(srcs, CompositeKeySep())
+	return compositeKeyWith(CompositeKeySep(), srcs...)
+}
+
+func compositeKeyWith(sep string, srcs ...string) string {
+	return strings.Join(srcs, sep)
+}
+
+// escapedCompositeKey joins escaped segments, see escapeSegment
+func escapedCompositeKey(sep string, srcs ...string) string {
+	escaped := make([]string, len(srcs))
+	for i, v := range srcs {
+		escaped[i] = escapeSegment(v, sep)
+	}
+	return strings.Join(escaped, sep)
+}
+
+// escapeSegment removes the separator's first rune r from s, with the escape rune e = r+1:
+// r -> e e, e -> e e+1. escaped segments keep their lexicographic order,
+// since the escaped runes sort right where r and e did.
+func escapeSegment(s, sep string) string {
+	r, _ := utf8.DecodeRuneInString(sep)
+	e := r + 1
+	if !strings.ContainsRune(s, r) && !strings.ContainsRune(s, e) {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s) + 4)
+	for _, c := range s {
+		switch c {
+		case r:
+			b.WriteRune(e)
+			b.WriteRune(e)
+		case e:
+			b.WriteRune(e)
+			b.WriteRune(e + 1)
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+func unescapeSegment(s, sep string) string {
+	r, _ := utf8.DecodeRuneInString(sep)
+	e := r + 1
+	if !strings.ContainsRune(s, e) {
+		return s
+	}
+	var (
+		b       strings.Builder
+		escaped bool
+	)
+	b.Grow(len(s))
+	for _, c := range s {
+		switch {
+		case escaped && c == e:
+			b.WriteRune(r)
+			escaped = false
+		case escaped && c == e+1:
+			b.WriteRune(e)
+			escaped = false
+		case escaped: // malformed, kept as is
+			b.WriteRune(e)
+			b.WriteRune(c)
+			escaped = false
+		case c == e:
+			escaped = true
+		default:
+			b.WriteRune(c)
+		}
+	}
+	if escaped {
+		b.WriteRune(e)
+	}
+	return b.String()
 }
 
 var embedCache sync.Map
@@ -94,6 +183,7 @@ func hasEmbeddedStruct(v any, targetPtr any) bool {
 	if v == nil || targetPtr == nil {
 		return false
 	}
+	v = untagAny(v)
 	src := indirectType(reflect.TypeOf(v))
 	tgt := indirectType(reflect.TypeOf(targetPtr))
 	if src.Kind() != reflect.Struct || tgt.Kind() != reflect.Struct {
@@ -140,6 +230,7 @@ func hasEmbeddedStruct_r(src, tgt reflect.Type, seen map[reflect.Type]struct{})
 }
 
 func callMethod(v any, targetPtr any, method string, args ...any) ([]any, error) {
+	v = untagAny(v)
 	if !hasEmbeddedStruct(v, targetPtr) {
 		return nil, errors.New("target type not embedded")
 	}
@@ -176,49 +267,14 @@ func callMethod(v any, targetPtr any, method string, args ...any) ([]any, error)
 	return result, nil
 }
 
-func uuidV4() string  { return uuid.NewString() }
-func ulidStr() string { return ulid.Make().String() }
-
-const maxInt = int(^uint(0) >> 1)
+func uuidV4() string { return uuid.NewString() }
 
-func joinOmitEmpty(sep string, s ...string) string {
-	switch len(s) {
-	case 0:
-		return ""
-	case 1:
-		return s[0]
-	}
-
-	var n int
-	if len(sep) > 0 {
-		if len(sep) >= maxInt/(len(s)-1) {
-			panic("strings: Join output length overflow")
-		}
-		n += len(sep) * (len(s) - 1)
-	}
-	for _, v := range s {
-		if len(v) > maxInt-n {
-			panic("strings: Join output length overflow")
-		}
-		if len(v) > 0 {
-			n += len(v)
-		}
-	}
-	var b strings.Builder
-	b.Grow(n)
-	first := true
-	for _, v := range s {
-		if len(v) == 0 {
-			continue
-		}
-		if first {
-			first = false
-		} else {
-			b.WriteString(sep)
-		}
-		b.WriteString(v)
+func ulidStr() string {
+	id, err := idSeqGen.next(GlobalClock().Now(), true)
+	if err != nil {
+		panic(err) // crypto/rand failed
 	}
-	return b.String()
+	return id.String()
 }
 
 func isNonNilPointer(output any) bool {
@@ -240,7 +296,7 @@ func structToUpdateBuilderOmitEmpty(item any, omitKeyOps ...string) (expression.
 	if item == nil {
 		return expression.UpdateBuilder{}, ErrEmptyForUpdate
 	}
-	rv := reflect.ValueOf(item)
+	rv := reflect.ValueOf(untagAny(item))
 	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
 		rv = reflect.Indirect(rv)
 	}
@@ -252,14 +308,42 @@ func structToUpdateBuilderOmitEmpty(item any, omitKeyOps ...string) (expression.
 	for _, key := range omitKeyOps {
 		omitKeys[key] = struct{}{}
 	}
-	update, count := structToUpdateBuilderOmitEmpty_r(rv, omitKeys)
+	update, count := structToUpdateBuilderOmitEmpty_r(expression.UpdateBuilder{}, rv, omitKeys)
 	if count == 0 {
 		return expression.UpdateBuilder{}, ErrEmptyForUpdate
 	}
 	return update, nil
 }
 
-func structToUpdateBuilderOmitEmpty_r(rv reflect.Value, omitKeys map[string]struct{}) (update expression.UpdateBuilder, count int) {
+// updateAttributes names the attributes structToUpdateBuilderOmitEmpty sets, the non-zero fields of item
+func updateAttributes(item any) map[string]struct{} {
+	set := make(map[string]struct{})
+	rv := reflect.Indirect(reflect.ValueOf(untagAny(item)))
+	if rv.Kind() == reflect.Struct {
+		updateAttributes_r(rv, set)
+	}
+	return set
+}
+
+func updateAttributes_r(rv reflect.Value, set map[string]struct{}) {
+	for i := range rv.Type().NumField() {
+		value := rv.Field(i)
+		if value.IsZero() {
+			continue
+		}
+		dynamoField := strings.TrimSuffix(rv.Type().Field(i).Tag.Get("dynamodbav"), ",omitempty")
+		switch {
+		case dynamoField == "-":
+		case value.Kind() == reflect.Struct:
+			updateAttributes_r(value, set)
+		case dynamoField != "":
+			set[dynamoField] = struct{}{}
+		}
+	}
+}
+
+func structToUpdateBuilderOmitEmpty_r(update expression.UpdateBuilder, rv reflect.Value, omitKeys map[string]struct{}) (expression.UpdateBuilder, int) {
+	count := 0
 	for i := range rv.Type().NumField() {
 		value := rv.Field(i)
 		if value.IsZero() {
@@ -274,7 +358,7 @@ func structToUpdateBuilderOmitEmpty_r(rv reflect.Value, omitKeys map[string]stru
 		}
 		if value.Kind() == reflect.Struct {
 			n := 0
-			update, n = structToUpdateBuilderOmitEmpty_r(value, omitKeys)
+			update, n = structToUpdateBuilderOmitEmpty_r(update, value, omitKeys)
 			count += n
 		} else {
 			if dynamoField == "" {